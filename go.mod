@@ -5,7 +5,9 @@ go 1.20
 require (
 	github.com/mattn/go-runewidth v0.0.15
 	github.com/urfave/cli/v2 v2.25.7
+	golang.org/x/crypto v0.12.0
 	golang.org/x/oauth2 v0.11.0
+	golang.org/x/sys v0.11.0
 	google.golang.org/api v0.138.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -25,9 +27,7 @@ require (
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/xrash/smetrics v0.0.0-20201216005158-039620a65673 // indirect
 	go.opencensus.io v0.24.0 // indirect
-	golang.org/x/crypto v0.12.0 // indirect
 	golang.org/x/net v0.14.0 // indirect
-	golang.org/x/sys v0.11.0 // indirect
 	golang.org/x/text v0.12.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20230807174057-1744710a1577 // indirect
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/t3yamoto/gt/internal/profile"
+	"golang.org/x/oauth2"
+)
+
+const (
+	tokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+	revokeURL    = "https://oauth2.googleapis.com/revoke"
+)
+
+// ErrNotSignedIn is returned when no token is stored
+var ErrNotSignedIn = errors.New("not signed in, run `gt auth login`")
+
+// Status describes the stored token and the account it belongs to
+type Status struct {
+	TokenStore      string // where the token is kept
+	Email           string // empty if the token was granted without the email scope
+	Scopes          []string
+	Expiry          time.Time
+	HasRefreshToken bool
+}
+
+// GetStatus returns information about the stored token, refreshing it first if it has expired
+func GetStatus(ctx context.Context) (*Status, error) {
+	p := profile.Current()
+	token, err := loadToken(p)
+	if err != nil {
+		if errors.Is(err, errNoToken) {
+			return nil, ErrNotSignedIn
+		}
+		return nil, err
+	}
+
+	store, err := newTokenStore(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid() && token.RefreshToken != "" {
+		config, err := loadCredentials(p)
+		if err != nil {
+			return nil, err
+		}
+		token, err = newSavingTokenSource(ctx, config, p, token).Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to refresh token: %w", err)
+		}
+	}
+
+	status := &Status{
+		TokenStore:      store.Location(),
+		Expiry:          token.Expiry,
+		HasRefreshToken: token.RefreshToken != "",
+	}
+
+	info, err := fetchTokenInfo(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	status.Email = info.Email
+	status.Scopes = strings.Fields(info.Scope)
+
+	return status, nil
+}
+
+// tokenInfo is the response of Google's tokeninfo endpoint
+type tokenInfo struct {
+	Email string `json:"email"`
+	Scope string `json:"scope"`
+}
+
+func fetchTokenInfo(ctx context.Context, token *oauth2.Token) (*tokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		tokenInfoURL+"?access_token="+url.QueryEscape(token.AccessToken), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get token info: %s", resp.Status)
+	}
+
+	var info tokenInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to parse token info: %w", err)
+	}
+	return &info, nil
+}
+
+// Logout deletes the current profile's stored token
+func Logout() error {
+	store, err := newTokenStore(profile.Current())
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(); err != nil {
+		if errors.Is(err, errNoToken) {
+			return ErrNotSignedIn
+		}
+		return err
+	}
+	return nil
+}
+
+// Revoke revokes the current profile's token at Google and deletes it
+func Revoke(ctx context.Context) error {
+	token, err := loadToken(profile.Current())
+	if err != nil {
+		if errors.Is(err, errNoToken) {
+			return ErrNotSignedIn
+		}
+		return err
+	}
+
+	// Revoking the refresh token also revokes the access tokens issued from it
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL,
+		strings.NewReader(url.Values{"token": {value}}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Google answers 400 for tokens that are already invalid, which is fine to clean up
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("failed to revoke token: %s", resp.Status)
+	}
+
+	return Logout()
+}
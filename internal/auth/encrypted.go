@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// encryptedSuffix is appended to token.json for the encrypted store
+	encryptedSuffix = ".enc"
+
+	// passphraseEnv supplies the passphrase non-interactively
+	passphraseEnv = "GT_TOKEN_PASSPHRASE"
+
+	encryptedVersion = 1
+
+	// scrypt parameters recommended for interactive logins
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 16
+)
+
+// errWrongPassphrase is returned when the token file cannot be decrypted
+var errWrongPassphrase = errors.New("failed to decrypt token: wrong passphrase or corrupted file")
+
+// encryptedFile is the on-disk format of the encrypted store
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// encryptedStore keeps the token in a file encrypted with AES-256-GCM, using a key
+// derived from a passphrase with scrypt
+type encryptedStore struct {
+	path string
+}
+
+// passphrase is remembered so a refreshed token can be saved without asking again
+var passphrase string
+
+func (s *encryptedStore) Load() (*oauth2.Token, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errNoToken
+		}
+		return nil, err
+	}
+
+	var f encryptedFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse token file: %w", err)
+	}
+	if f.Version != encryptedVersion || f.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported token file format: %s", s.path)
+	}
+
+	pass, err := getPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key([]byte(pass), f.Salt, f.N, f.R, f.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		passphrase = ""
+		return nil, errWrongPassphrase
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(plain, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+	return &token, nil
+}
+
+func (s *encryptedStore) Save(token *oauth2.Token) error {
+	plain, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	// Unless it was just used to load the token, the passphrase is being chosen now
+	pass, err := getPassphrase(true)
+	if err != nil {
+		return err
+	}
+
+	f := encryptedFile{
+		Version: encryptedVersion,
+		KDF:     "scrypt",
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    make([]byte, saltLen),
+	}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return err
+	}
+	key, err := scrypt.Key([]byte(pass), f.Salt, f.N, f.R, f.P, scryptKeyLen)
+	if err != nil {
+		return fmt.Errorf("failed to derive key: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Ciphertext = gcm.Seal(nil, f.Nonce, plain, nil)
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return writeTokenFile(s.path, b)
+}
+
+func (s *encryptedStore) Delete() error {
+	return removeTokenFile(s.path)
+}
+
+func (s *encryptedStore) Location() string {
+	return s.path + " (encrypted)"
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// getPassphrase returns the passphrase from GT_TOKEN_PASSPHRASE, or prompts for it.
+// With confirm set, a prompted passphrase must be entered twice.
+func getPassphrase(confirm bool) (string, error) {
+	if pass := os.Getenv(passphraseEnv); pass != "" {
+		return pass, nil
+	}
+	if passphrase != "" {
+		return passphrase, nil
+	}
+
+	pass, err := readPassphrase("Token passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if pass == "" {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+	if confirm {
+		again, err := readPassphrase("Confirm passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != pass {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	passphrase = pass
+	return pass, nil
+}
+
+// readPassphrase reads a line from the terminal with echo turned off
+func readPassphrase(prompt string) (string, error) {
+	noTTY := fmt.Errorf("no terminal to read the token passphrase from, set %s", passphraseEnv)
+	if runtime.GOOS == "windows" {
+		return "", noTTY
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", noTTY
+	}
+	defer tty.Close()
+
+	fmt.Fprint(tty, prompt)
+	if err := stty(tty, "-echo"); err == nil {
+		defer func() {
+			stty(tty, "echo")
+			fmt.Fprintln(tty)
+		}()
+	}
+
+	line, err := bufio.NewReader(tty).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func stty(tty *os.File, arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = tty
+	return cmd.Run()
+}
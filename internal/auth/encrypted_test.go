@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// usePassphrase supplies pass through GT_TOKEN_PASSPHRASE and forgets any remembered one
+func usePassphrase(t *testing.T, pass string) {
+	t.Helper()
+	t.Setenv(passphraseEnv, pass)
+	passphrase = ""
+	t.Cleanup(func() { passphrase = "" })
+}
+
+func TestEncryptedStore(t *testing.T) {
+	store := &encryptedStore{path: filepath.Join(t.TempDir(), "gt", "token.json.enc")}
+	token := &oauth2.Token{
+		AccessToken:  "access-123",
+		RefreshToken: "refresh-456",
+		TokenType:    "Bearer",
+		Expiry:       time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	usePassphrase(t, "correct horse")
+	if _, err := store.Load(); !errors.Is(err, errNoToken) {
+		t.Fatalf("Load before Save: err = %v, want errNoToken", err)
+	}
+	if err := store.Save(token); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	b, err := os.ReadFile(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), token.RefreshToken) || strings.Contains(string(b), token.AccessToken) {
+		t.Errorf("token file contains the token in plain text: %s", b)
+	}
+	if info, err := os.Stat(store.path); err != nil {
+		t.Fatal(err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		t.Errorf("token file mode = %v, want no access for others", info.Mode().Perm())
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.AccessToken != token.AccessToken || loaded.RefreshToken != token.RefreshToken ||
+		loaded.TokenType != token.TokenType || !loaded.Expiry.Equal(token.Expiry) {
+		t.Errorf("Load = %+v, want %+v", loaded, token)
+	}
+
+	usePassphrase(t, "wrong horse")
+	if _, err := store.Load(); !errors.Is(err, errWrongPassphrase) {
+		t.Errorf("Load with wrong passphrase: err = %v, want errWrongPassphrase", err)
+	}
+
+	if err := store.Delete(); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(); !errors.Is(err, errNoToken) {
+		t.Errorf("second Delete: err = %v, want errNoToken", err)
+	}
+}
+
+func TestEncryptedStoreRejectsTamperedFile(t *testing.T) {
+	store := &encryptedStore{path: filepath.Join(t.TempDir(), "token.json.enc")}
+	usePassphrase(t, "correct horse")
+	if err := store.Save(&oauth2.Token{AccessToken: "a", RefreshToken: "r"}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Flip a character of the base64 ciphertext
+	i := strings.Index(string(b), `"ciphertext":"`) + len(`"ciphertext":"`)
+	flipped := byte('A')
+	if b[i] == 'A' {
+		flipped = 'B'
+	}
+	b[i] = flipped
+	if err := os.WriteFile(store.path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Load(); !errors.Is(err, errWrongPassphrase) {
+		t.Errorf("Load of a tampered file: err = %v, want errWrongPassphrase", err)
+	}
+}
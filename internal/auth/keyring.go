@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/t3yamoto/gt/internal/profile"
+	"golang.org/x/oauth2"
+)
+
+// keyringService is the service name tokens are stored under in the OS keyring
+const keyringService = "gt"
+
+// keyringStore keeps the token in the OS keyring: the Secret Service on Linux
+// (via secret-tool) or the login keychain on macOS (via security)
+type keyringStore struct {
+	account string
+}
+
+func newKeyringStore(p profile.Profile) *keyringStore {
+	account := p.Name
+	if account == "" {
+		account = profile.DefaultName
+	}
+	return &keyringStore{account: account}
+}
+
+func (s *keyringStore) Load() (*oauth2.Token, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "profile", s.account)
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", s.account, "-w")
+	default:
+		return nil, errKeyringUnsupported
+	}
+
+	out, err := runKeyring(cmd, nil)
+	if err != nil {
+		// Both tools exit with a non-zero status when the item does not exist
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, errNoToken
+		}
+		return nil, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, errNoToken
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(out, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token from keyring: %w", err)
+	}
+	return &token, nil
+}
+
+func (s *keyringStore) Save(token *oauth2.Token) error {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	var stdin []byte
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("secret-tool", "store", "--label", "gt token ("+s.account+")",
+			"service", keyringService, "profile", s.account)
+		stdin = b
+	case "darwin":
+		// Arguments are visible to other users in ps, so the command is given on stdin
+		// to security's interactive mode, with the token hex-encoded to avoid quoting
+		cmd = exec.Command("security", "-i")
+		stdin = []byte(fmt.Sprintf("add-generic-password -U -s %s -a %s -l \"gt token (%s)\" -X %s\n",
+			keyringService, s.account, s.account, hex.EncodeToString(b)))
+	default:
+		return errKeyringUnsupported
+	}
+
+	if _, err := runKeyring(cmd, stdin); err != nil {
+		return fmt.Errorf("failed to save token to keyring: %w", err)
+	}
+
+	if runtime.GOOS == "darwin" {
+		// security -i does not fail when a command in it fails, so read the token back
+		saved, err := s.Load()
+		if err != nil {
+			return fmt.Errorf("failed to save token to keyring: %w", err)
+		}
+		if saved.AccessToken != token.AccessToken || saved.RefreshToken != token.RefreshToken {
+			return errors.New("failed to save token to keyring: a different token was read back")
+		}
+	}
+	return nil
+}
+
+func (s *keyringStore) Delete() error {
+	if _, err := s.Load(); err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("secret-tool", "clear", "service", keyringService, "profile", s.account)
+	case "darwin":
+		cmd = exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", s.account)
+	default:
+		return errKeyringUnsupported
+	}
+
+	if _, err := runKeyring(cmd, nil); err != nil {
+		return fmt.Errorf("failed to delete token from keyring: %w", err)
+	}
+	return nil
+}
+
+func (s *keyringStore) Location() string {
+	return fmt.Sprintf("OS keyring (service %q, account %q)", keyringService, s.account)
+}
+
+var errKeyringUnsupported = fmt.Errorf("the keyring token store is not supported on %s, use the encrypted store instead", runtime.GOOS)
+
+// runKeyring runs a keyring tool, returning its standard output
+func runKeyring(cmd *exec.Cmd, stdin []byte) ([]byte, error) {
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%s not found: install it or use the encrypted token store", cmd.Path)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+	return out, nil
+}
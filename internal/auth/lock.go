@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/t3yamoto/gt/internal/profile"
+)
+
+// lockSuffix is appended to token.json for the file that serializes token updates
+const lockSuffix = ".lock"
+
+// lockToken takes an exclusive lock on the profile's token, blocking until other gt
+// processes have finished refreshing or saving it. The returned func releases the lock.
+func lockToken(p profile.Profile) (func(), error) {
+	path, err := getTokenPath(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path+lockSuffix, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock token: %w", err)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
@@ -1,54 +1,74 @@
 package auth
 
 import (
+	"bufio"
 	"context"
-	"encoding/json"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"html"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
+	"github.com/t3yamoto/gt/internal/profile"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/tasks/v1"
 )
 
 const (
-	configDir       = ".config/gt"
 	credentialsFile = "credentials.json"
 	tokenFile       = "token.json"
+
+	// manualRedirectURL is the loopback redirect used by the copy-paste code flow.
+	// Nothing listens on it; the user copies the code from the failed page's URL.
+	manualRedirectURL = "http://localhost:8085/callback"
+
+	emailScope = "https://www.googleapis.com/auth/userinfo.email"
+
+	// loginTimeout bounds how long the browser flow waits for the redirect
+	loginTimeout = 5 * time.Minute
 )
 
-func getConfigDir() (string, error) {
-	home, err := os.UserHomeDir()
+// getCredentialsPath returns the profile's credentials.json, falling back to the
+// shared one so profiles can reuse a single OAuth client
+func getCredentialsPath(p profile.Profile) (string, error) {
+	dir, err := p.ConfigDir()
 	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+		return "", err
+	}
+	path := filepath.Join(dir, credentialsFile)
+	if _, err := os.Stat(path); err == nil || p.IsDefault() {
+		return path, nil
 	}
-	return filepath.Join(home, configDir), nil
-}
 
-func getCredentialsPath() (string, error) {
-	dir, err := getConfigDir()
+	shared, err := profile.SharedConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, credentialsFile), nil
+	return filepath.Join(shared, credentialsFile), nil
 }
 
-func getTokenPath() (string, error) {
-	dir, err := getConfigDir()
+func getTokenPath(p profile.Profile) (string, error) {
+	dir, err := p.ConfigDir()
 	if err != nil {
 		return "", err
 	}
 	return filepath.Join(dir, tokenFile), nil
 }
 
-func loadCredentials() (*oauth2.Config, error) {
-	path, err := getCredentialsPath()
+func loadCredentials(p profile.Profile) (*oauth2.Config, error) {
+	path, err := getCredentialsPath(p)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +78,8 @@ func loadCredentials() (*oauth2.Config, error) {
 		return nil, fmt.Errorf("credentials.json not found: %s\nPlease download it from Google Cloud Console", path)
 	}
 
-	config, err := google.ConfigFromJSON(b, tasks.TasksScope)
+	// The email scope lets `gt auth status` show which account is signed in
+	config, err := google.ConfigFromJSON(b, tasks.TasksScope, emailScope)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse credentials.json: %w", err)
 	}
@@ -66,130 +87,269 @@ func loadCredentials() (*oauth2.Config, error) {
 	return config, nil
 }
 
-func loadToken() (*oauth2.Token, error) {
-	path, err := getTokenPath()
+// LoginOptions controls how Login authenticates
+type LoginOptions struct {
+	// Manual uses the copy-paste code flow instead of opening a browser
+	Manual bool
+}
+
+// Login authenticates interactively and saves the resulting token.
+// Without a display (e.g. over SSH) it falls back to the copy-paste code flow.
+func Login(ctx context.Context, opts LoginOptions) error {
+	p := profile.Current()
+	config, err := loadCredentials(p)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	f, err := os.Open(path)
+	_, err = authenticateAndSave(ctx, config, p, opts.Manual || isHeadless())
+	return err
+}
+
+// GetClient returns an authenticated HTTP client for the current profile.
+// If no token exists or it's expired, it automatically triggers browser authentication.
+func GetClient(ctx context.Context) (*http.Client, error) {
+	return GetClientForProfile(ctx, profile.Current())
+}
+
+// GetClientForProfile returns an authenticated HTTP client for the given profile
+func GetClientForProfile(ctx context.Context, p profile.Profile) (*http.Client, error) {
+	config, err := loadCredentials(p)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
-	var token oauth2.Token
-	if err := json.NewDecoder(f).Decode(&token); err != nil {
+	token, err := loadToken(p)
+	if errors.Is(err, errNoToken) {
+		// No token, authenticate via browser
+		token, err = authenticateAndSave(ctx, config, p, isHeadless())
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	return &token, nil
+	ts := newSavingTokenSource(ctx, config, p, token)
+	if _, err := ts.Token(); err != nil {
+		// Refresh failed, re-authenticate via browser
+		token, err = authenticateAndSave(ctx, config, p, isHeadless())
+		if err != nil {
+			return nil, err
+		}
+		ts = newSavingTokenSource(ctx, config, p, token)
+	}
+
+	return oauth2.NewClient(ctx, ts), nil
 }
 
-func saveToken(token *oauth2.Token) error {
-	dir, err := getConfigDir()
+// authenticateAndSave signs in interactively and saves the token while holding the token lock
+func authenticateAndSave(ctx context.Context, config *oauth2.Config, p profile.Profile, manual bool) (*oauth2.Token, error) {
+	token, err := authenticate(ctx, config, manual)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := os.MkdirAll(dir, 0700); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+	unlock, err := lockToken(p)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
-	path, err := getTokenPath()
+	if err := saveToken(p, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// authenticate runs the browser flow, or the copy-paste code flow if manual is set
+func authenticate(ctx context.Context, config *oauth2.Config, manual bool) (*oauth2.Token, error) {
+	if manual {
+		return authenticateManually(ctx, config)
+	}
+	return authenticateViaBrowser(ctx, config)
+}
+
+// isHeadless reports whether there is likely no local browser, e.g. in an SSH session
+func isHeadless() bool {
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" {
+		return true
+	}
+	if runtime.GOOS == "linux" {
+		return os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+	}
+	return false
+}
+
+// authenticateManually performs OAuth authentication without a local browser.
+// Google does not allow the Tasks scope in the device code flow, so the user opens the
+// URL on any machine and pastes back the loopback redirect URL (or just its code).
+func authenticateManually(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	config.RedirectURL = manualRedirectURL
+
+	flow, err := newAuthFlow()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	fmt.Printf("Open the following URL in a browser on any machine:\n\n%s\n\n", flow.authCodeURL(config))
+	fmt.Println("After approving access, the browser is redirected to a localhost page that fails to load.")
+	fmt.Print("Copy the full URL from the address bar and paste it here: ")
+
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && input == "" {
+		return nil, fmt.Errorf("failed to read authorization code: %w", err)
 	}
 
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	authCode, err := flow.parseAuthCode(strings.TrimSpace(input))
 	if err != nil {
-		return fmt.Errorf("failed to create token file: %w", err)
+		return nil, err
 	}
-	defer f.Close()
 
-	return json.NewEncoder(f).Encode(token)
+	return flow.exchange(ctx, config, authCode)
 }
 
-// GetClient returns an authenticated HTTP client.
-// If no token exists or it's expired, it automatically triggers browser authentication.
-func GetClient(ctx context.Context) (*http.Client, error) {
-	config, err := loadCredentials()
+// authFlow holds the per-login CSRF state and PKCE code verifier
+type authFlow struct {
+	state    string
+	verifier string
+}
+
+func newAuthFlow() (*authFlow, error) {
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomString()
 	if err != nil {
 		return nil, err
 	}
+	return &authFlow{state: state, verifier: verifier}, nil
+}
 
-	token, err := loadToken()
+// randomString returns 32 random bytes, base64url-encoded. This is also a valid
+// PKCE code verifier (43 characters from the unreserved set).
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// authCodeURL returns the consent page URL with the state and S256 code challenge
+func (f *authFlow) authCodeURL(config *oauth2.Config) string {
+	sum := sha256.Sum256([]byte(f.verifier))
+	return config.AuthCodeURL(f.state, oauth2.AccessTypeOffline,
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(sum[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"))
+}
+
+// exchange trades the authorization code for a token, proving possession of the verifier
+func (f *authFlow) exchange(ctx context.Context, config *oauth2.Config, code string) (*oauth2.Token, error) {
+	token, err := config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", f.verifier))
 	if err != nil {
-		// No token, authenticate via browser
-		token, err = authenticateViaBrowser(ctx, config)
-		if err != nil {
-			return nil, err
-		}
-		if err := saveToken(token); err != nil {
-			return nil, err
-		}
-	} else if token.Expiry.Before(time.Now()) && token.RefreshToken != "" {
-		// Token expired, try to refresh
-		tokenSource := config.TokenSource(ctx, token)
-		newToken, err := tokenSource.Token()
-		if err != nil {
-			// Refresh failed, re-authenticate via browser
-			newToken, err = authenticateViaBrowser(ctx, config)
-			if err != nil {
-				return nil, err
-			}
-		}
-		if err := saveToken(newToken); err != nil {
-			return nil, err
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	return token, nil
+}
+
+// codeFromQuery returns the authorization code from a redirect's query parameters,
+// rejecting errors reported by Google and responses to a different login attempt
+func (f *authFlow) codeFromQuery(q url.Values) (string, error) {
+	if e := q.Get("error"); e != "" {
+		if desc := q.Get("error_description"); desc != "" {
+			e += ": " + desc
 		}
-		token = newToken
+		return "", fmt.Errorf("authorization denied: %s", e)
+	}
+	if subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(f.state)) != 1 {
+		return "", fmt.Errorf("authorization failed: state mismatch, please sign in again")
+	}
+	code := q.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("failed to get authorization code")
+	}
+	return code, nil
+}
+
+// parseAuthCode extracts the authorization code from a pasted redirect URL or bare code.
+// A bare code cannot be checked against the state, but PKCE still ties it to this login.
+func (f *authFlow) parseAuthCode(input string) (string, error) {
+	if input == "" {
+		return "", fmt.Errorf("failed to get authorization code")
+	}
+	if !strings.Contains(input, "://") {
+		return input, nil
 	}
 
-	return config.Client(ctx, token), nil
+	u, err := url.Parse(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse redirect URL: %w", err)
+	}
+	return f.codeFromQuery(u.Query())
 }
 
 // authenticateViaBrowser performs OAuth authentication via browser
 func authenticateViaBrowser(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
-	// Find an available port
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+
+	// Listen on an available port and keep the listener, so no other process can take it
 	listener, err := net.Listen("tcp", "localhost:0")
 	if err != nil {
 		return nil, fmt.Errorf("failed to start local server: %w", err)
 	}
 	port := listener.Addr().(*net.TCPAddr).Port
-	listener.Close()
 
 	// Set redirect URL
 	config.RedirectURL = fmt.Sprintf("http://localhost:%d/callback", port)
 
-	// Channel to receive auth code
-	codeCh := make(chan string)
-	errCh := make(chan error)
+	flow, err := newAuthFlow()
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	// Buffered so the handler never blocks once a result has been delivered
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
 
 	// Start local server for callback
 	mux := http.NewServeMux()
 	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
-		code := r.URL.Query().Get("code")
-		if code == "" {
-			errCh <- fmt.Errorf("failed to get authorization code")
-			fmt.Fprintf(w, "<html><body><h1>Authentication Failed</h1><p>You can close this window.</p></body></html>")
+		code, err := flow.codeFromQuery(r.URL.Query())
+		if err != nil {
+			select {
+			case errCh <- err:
+			default:
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<html><body><h1>Authentication Failed</h1><p>%s</p><p>You can close this window.</p></body></html>",
+				html.EscapeString(err.Error()))
 			return
 		}
-		codeCh <- code
+		select {
+		case codeCh <- code:
+		default:
+		}
 		fmt.Fprintf(w, "<html><body><h1>Authentication Successful!</h1><p>You can close this window.</p></body></html>")
 	})
 	server := &http.Server{
-		Addr:    fmt.Sprintf("localhost:%d", port),
-		Handler: mux,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
 	}
 
 	go func() {
-		if err := server.ListenAndServe(); err != http.ErrServerClosed {
-			errCh <- err
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			select {
+			case errCh <- err:
+			default:
+			}
 		}
 	}()
+	defer server.Close()
 
 	// Generate auth URL and open browser
-	authURL := config.AuthCodeURL("state", oauth2.AccessTypeOffline)
+	authURL := flow.authCodeURL(config)
 	fmt.Println("Please authenticate in your browser...")
 	if err := openBrowser(authURL); err != nil {
 		fmt.Printf("Could not open browser. Please open the following URL manually:\n%s\n", authURL)
@@ -200,22 +360,16 @@ func authenticateViaBrowser(ctx context.Context, config *oauth2.Config) (*oauth2
 	select {
 	case authCode = <-codeCh:
 	case err := <-errCh:
-		server.Shutdown(ctx)
 		return nil, err
 	case <-ctx.Done():
-		server.Shutdown(ctx)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s waiting for authentication", loginTimeout)
+		}
 		return nil, ctx.Err()
 	}
 
-	server.Shutdown(ctx)
-
 	// Exchange auth code for token
-	token, err := config.Exchange(ctx, authCode)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get token: %w", err)
-	}
-
-	return token, nil
+	return flow.exchange(ctx, config, authCode)
 }
 
 // openBrowser opens the specified URL in the default browser
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/t3yamoto/gt/internal/profile"
+	"golang.org/x/oauth2"
+)
+
+// Token store backends
+const (
+	StoreFile      = "file"
+	StoreKeyring   = "keyring"
+	StoreEncrypted = "encrypted"
+)
+
+// errNoToken is returned by a TokenStore that holds no token
+var errNoToken = errors.New("no token stored")
+
+// TokenStore persists a profile's OAuth token
+type TokenStore interface {
+	// Load returns the stored token, or errNoToken if there is none
+	Load() (*oauth2.Token, error)
+	Save(token *oauth2.Token) error
+	// Delete removes the stored token, returning errNoToken if there is none
+	Delete() error
+	// Location describes where the token is kept, for `gt auth status`
+	Location() string
+}
+
+// storeKind is the backend used for tokens, set with SetTokenStore
+var storeKind = StoreFile
+
+// SetTokenStore selects the token store backend for this invocation
+func SetTokenStore(kind string) error {
+	switch kind {
+	case "":
+		storeKind = StoreFile
+	case StoreFile, StoreKeyring, StoreEncrypted:
+		storeKind = kind
+	default:
+		return fmt.Errorf("unknown token store '%s': use %s, %s or %s", kind, StoreFile, StoreKeyring, StoreEncrypted)
+	}
+	return nil
+}
+
+// newTokenStore returns the selected token store for the given profile
+func newTokenStore(p profile.Profile) (TokenStore, error) {
+	switch storeKind {
+	case StoreKeyring:
+		return newKeyringStore(p), nil
+	case StoreEncrypted:
+		path, err := getTokenPath(p)
+		if err != nil {
+			return nil, err
+		}
+		return &encryptedStore{path: path + encryptedSuffix}, nil
+	}
+
+	path, err := getTokenPath(p)
+	if err != nil {
+		return nil, err
+	}
+	return &fileStore{path: path}, nil
+}
+
+// loadToken loads the profile's token from the selected store, first moving a
+// plain token.json into it if the store is empty
+func loadToken(p profile.Profile) (*oauth2.Token, error) {
+	store, err := newTokenStore(p)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := store.Load()
+	if !errors.Is(err, errNoToken) {
+		return token, err
+	}
+	if _, ok := store.(*fileStore); ok {
+		return nil, err
+	}
+	return migrateToken(p, store)
+}
+
+func saveToken(p profile.Profile, token *oauth2.Token) error {
+	store, err := newTokenStore(p)
+	if err != nil {
+		return err
+	}
+	return store.Save(token)
+}
+
+// migrateToken moves a plain token.json into store and deletes the file
+func migrateToken(p profile.Profile, store TokenStore) (*oauth2.Token, error) {
+	path, err := getTokenPath(p)
+	if err != nil {
+		return nil, err
+	}
+	plain := &fileStore{path: path}
+
+	token, err := plain.Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Save(token); err != nil {
+		return nil, fmt.Errorf("failed to migrate token: %w", err)
+	}
+	if err := plain.Delete(); err != nil {
+		return nil, fmt.Errorf("failed to delete migrated token file: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Moved token from %s to %s.\n", path, store.Location())
+	return token, nil
+}
+
+// fileStore keeps the token as plain JSON, readable only by the user
+type fileStore struct {
+	path string
+}
+
+func (s *fileStore) Load() (*oauth2.Token, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errNoToken
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var token oauth2.Token
+	if err := json.NewDecoder(f).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to parse token file: %w", err)
+	}
+
+	return &token, nil
+}
+
+func (s *fileStore) Save(token *oauth2.Token) error {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return writeTokenFile(s.path, b)
+}
+
+func (s *fileStore) Delete() error {
+	return removeTokenFile(s.path)
+}
+
+func (s *fileStore) Location() string {
+	return s.path
+}
+
+// writeTokenFile creates the token's directory and replaces the file with data,
+// readable only by the user. The file is written to a temporary name and renamed
+// so other gt processes never read a partial token.
+func writeTokenFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create token file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	return nil
+}
+
+func removeTokenFile(path string) error {
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return errNoToken
+		}
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	return nil
+}
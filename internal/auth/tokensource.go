@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/t3yamoto/gt/internal/profile"
+	"golang.org/x/oauth2"
+)
+
+// savingTokenSource refreshes the token when it expires and saves every newly minted
+// token, so later invocations reuse it instead of refreshing again. Refreshes are
+// serialized across gt processes with the token lock.
+type savingTokenSource struct {
+	ctx    context.Context
+	config *oauth2.Config
+	p      profile.Profile
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func newSavingTokenSource(ctx context.Context, config *oauth2.Config, p profile.Profile, token *oauth2.Token) *savingTokenSource {
+	return &savingTokenSource{ctx: ctx, config: config, p: p, token: token}
+}
+
+// Token returns a valid token, refreshing and saving it if needed
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	unlock, err := lockToken(s.p)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Another gt process may have refreshed the token while we waited for the lock
+	if stored, err := loadToken(s.p); err == nil && stored.Valid() {
+		s.token = stored
+		return stored, nil
+	}
+
+	token, err := s.config.TokenSource(s.ctx, s.token).Token()
+	if err != nil {
+		return nil, err
+	}
+	if err := saveToken(s.p, token); err != nil {
+		// The refreshed token still works for this invocation
+		fmt.Fprintf(os.Stderr, "Warning: failed to save refreshed token: %v\n", err)
+	}
+	s.token = token
+	return token, nil
+}
@@ -5,14 +5,13 @@ import (
 	"os"
 	"path/filepath"
 	"time"
-)
 
-const (
-	cacheDir  = ".cache/gt"
-	cacheFile = "cache.json"
-	cacheTTL  = 5 * time.Minute
+	"github.com/t3yamoto/gt/internal/config"
+	"github.com/t3yamoto/gt/internal/profile"
 )
 
+const cacheFile = "cache.json"
+
 // TaskListCache represents a cached task list
 type TaskListCache struct {
 	ID    string `json:"id"`
@@ -26,6 +25,10 @@ type TaskCache struct {
 	Notes        string `json:"notes"`
 	Due          string `json:"due"`
 	Status       string `json:"status"`
+	Parent       string `json:"parent,omitempty"`
+	Position     string `json:"position,omitempty"`
+	Etag         string `json:"etag,omitempty"`
+	Updated      string `json:"updated,omitempty"`
 	TaskListID   string `json:"task_list_id"`
 	TaskListName string `json:"task_list_name"`
 }
@@ -35,6 +38,8 @@ type CacheData struct {
 	TaskLists []TaskListCache `json:"task_lists"`
 	Tasks     []TaskCache     `json:"tasks"`
 	CachedAt  time.Time       `json:"cached_at"`
+	// SyncedAt records, per task list ID, the time up to which tasks are known to be in sync
+	SyncedAt map[string]time.Time `json:"synced_at,omitempty"`
 }
 
 // Cache provides file-based caching
@@ -42,14 +47,18 @@ type Cache struct {
 	path string
 }
 
-// New creates a new Cache instance
+// New creates a new Cache instance for the current profile
 func New() (*Cache, error) {
-	home, err := os.UserHomeDir()
+	return NewForProfile(profile.Current())
+}
+
+// NewForProfile creates a new Cache instance for the given profile
+func NewForProfile(p profile.Profile) (*Cache, error) {
+	dir, err := p.CacheDir()
 	if err != nil {
 		return nil, err
 	}
 
-	dir := filepath.Join(home, cacheDir)
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return nil, err
 	}
@@ -61,6 +70,22 @@ func New() (*Cache, error) {
 
 // Load reads cache from file, returns nil if expired or not found
 func (c *Cache) Load() *CacheData {
+	cache := c.LoadStale()
+	if cache == nil {
+		return nil
+	}
+
+	// Check TTL
+	if time.Since(cache.CachedAt) > config.CacheTTL() {
+		return nil
+	}
+
+	return cache
+}
+
+// LoadStale reads cache from file regardless of its age, returns nil if not found.
+// It is used as the base for an incremental sync once the cache has expired.
+func (c *Cache) LoadStale() *CacheData {
 	data, err := os.ReadFile(c.path)
 	if err != nil {
 		return nil
@@ -71,11 +96,6 @@ func (c *Cache) Load() *CacheData {
 		return nil
 	}
 
-	// Check TTL
-	if time.Since(cache.CachedAt) > cacheTTL {
-		return nil
-	}
-
 	return &cache
 }
 
@@ -134,6 +154,62 @@ func (c *Cache) RemoveTask(taskID string) {
 	}
 }
 
+// AddTaskList adds a task list to the cache
+func (c *Cache) AddTaskList(list TaskListCache) {
+	data := c.Load()
+	if data == nil {
+		return
+	}
+
+	data.TaskLists = append(data.TaskLists, list)
+	c.Save(data)
+}
+
+// UpdateTaskList updates a task list in the cache, including the list name of its tasks
+func (c *Cache) UpdateTaskList(list TaskListCache) {
+	data := c.Load()
+	if data == nil {
+		return
+	}
+
+	for i, tl := range data.TaskLists {
+		if tl.ID == list.ID {
+			data.TaskLists[i] = list
+		}
+	}
+	for i, t := range data.Tasks {
+		if t.TaskListID == list.ID {
+			data.Tasks[i].TaskListName = list.Title
+		}
+	}
+	c.Save(data)
+}
+
+// RemoveTaskList removes a task list and its tasks from the cache
+func (c *Cache) RemoveTaskList(listID string) {
+	data := c.Load()
+	if data == nil {
+		return
+	}
+
+	var lists []TaskListCache
+	for _, tl := range data.TaskLists {
+		if tl.ID != listID {
+			lists = append(lists, tl)
+		}
+	}
+	var tasks []TaskCache
+	for _, t := range data.Tasks {
+		if t.TaskListID != listID {
+			tasks = append(tasks, t)
+		}
+	}
+
+	data.TaskLists = lists
+	data.Tasks = tasks
+	c.Save(data)
+}
+
 // Invalidate removes the cache file
 func (c *Cache) Invalidate() error {
 	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
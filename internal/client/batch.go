@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// TaskNode is a task to create with CreateTasks, together with its subtasks
+type TaskNode struct {
+	Task     *Task
+	Subtasks []*TaskNode
+}
+
+// siblingChain is a run of sibling tasks created in order under one parent
+type siblingChain struct {
+	parentID string
+	nodes    []*TaskNode
+}
+
+// CreateTasks creates a tree of tasks in one task list, keeping the order of siblings
+// and the hierarchy. The parent of top-level nodes may be set with Task.Parent (a
+// short or full ID); subtasks always go under their node. Siblings are created one
+// after the other, while the subtasks of different parents are created concurrently,
+// bounded by the client's worker count.
+//
+// The created tasks are returned in depth-first order. On error, the tasks created
+// so far are returned along with it.
+func (c *Client) CreateTasks(ctx context.Context, taskListID string, roots []*TaskNode) ([]*Task, error) {
+	if len(roots) == 0 {
+		return nil, nil
+	}
+
+	listName, _ := c.GetTaskListName(ctx, taskListID)
+
+	// Top-level nodes share one parent, resolved once
+	rootParent := ""
+	if p := roots[0].Task.Parent; p != "" {
+		id, err := c.ResolveTaskID(ctx, taskListID, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve parent task: %w", err)
+		}
+		rootParent = id
+	}
+
+	var mu sync.Mutex
+	created := make(map[*TaskNode]*Task)
+
+	createChain := func(chain siblingChain) error {
+		previous := ""
+		for _, node := range chain.nodes {
+			t, err := c.insertTask(ctx, taskListID, listName, node.Task, chain.parentID, previous)
+			if err != nil {
+				return fmt.Errorf("failed to create task '%s': %w", node.Task.Title, err)
+			}
+			mu.Lock()
+			created[node] = t
+			mu.Unlock()
+			previous = t.ID
+		}
+		return nil
+	}
+
+	// Create one level of the tree at a time, as subtasks need their parent's ID
+	var err error
+	level := []siblingChain{{parentID: rootParent, nodes: roots}}
+	for len(level) > 0 && err == nil {
+		err = c.forEachChain(ctx, level, createChain)
+
+		var next []siblingChain
+		for _, chain := range level {
+			for _, node := range chain.nodes {
+				if t, ok := created[node]; ok && len(node.Subtasks) > 0 {
+					next = append(next, siblingChain{parentID: t.ID, nodes: node.Subtasks})
+				}
+			}
+		}
+		level = next
+	}
+
+	// Collect in document order and update the cache once, from this goroutine
+	var result []*Task
+	var collect func(nodes []*TaskNode)
+	collect = func(nodes []*TaskNode) {
+		for _, node := range nodes {
+			if t, ok := created[node]; ok {
+				result = append(result, t)
+				c.addTaskToCache(t)
+			}
+			collect(node.Subtasks)
+		}
+	}
+	collect(roots)
+
+	return result, err
+}
+
+// forEachChain runs fn for every chain, bounded by the client's worker count
+func (c *Client) forEachChain(ctx context.Context, chains []siblingChain, fn func(siblingChain) error) error {
+	errs := make([]error, len(chains))
+	sem := make(chan struct{}, c.workers)
+
+	var wg sync.WaitGroup
+	for i, chain := range chains {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, chain siblingChain) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(chain)
+		}(i, chain)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -1,10 +1,35 @@
 package client
 
+import (
+	"fmt"
+	"time"
+)
+
 // Task list constants
 const (
 	DefaultTaskList = "@default"
 )
 
+// Paging constants
+const (
+	// DefaultMaxResults is the page size requested from the API (the API maximum)
+	DefaultMaxResults = 100
+)
+
+// Concurrency constants
+const (
+	// DefaultWorkers is the number of task lists fetched concurrently
+	DefaultWorkers = 4
+)
+
+// Sync constants
+const (
+	// syncSkew is subtracted from sync watermarks to allow for clock skew with the API
+	syncSkew = time.Minute
+	// maxSyncAge is the age after which a watermark is ignored and a list is fetched in full
+	maxSyncAge = 7 * 24 * time.Hour
+)
+
 // Task status constants
 const (
 	StatusNeedsAction = "needsAction"
@@ -26,3 +51,16 @@ func ParseDueDate(apiDate string) string {
 	}
 	return apiDate[:10]
 }
+
+// formatDateBound converts a date string (YYYY-MM-DD) to an RFC 3339 timestamp at the
+// start of that day in local time, or at the start of the following day if endOfDay is set
+func formatDateBound(date string, endOfDay bool) (string, error) {
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return "", fmt.Errorf("invalid date '%s', expected YYYY-MM-DD", date)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t.Format(time.RFC3339), nil
+}
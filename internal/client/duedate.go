@@ -0,0 +1,190 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	// relativePattern matches offsets such as +3d, 2w, -1m or +1y
+	relativePattern = regexp.MustCompile(`^([+-]?)(\d+)\s*([dwmy])$`)
+	// ordinalPattern matches a day of the month such as 1st or 22nd
+	ordinalPattern = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)$`)
+	// compactPattern matches YYYYMMDD
+	compactPattern = regexp.MustCompile(`^(\d{4})(\d{2})(\d{2})$`)
+	// isoPattern matches YYYY-M-D with -, / or . separators
+	isoPattern = regexp.MustCompile(`^(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseDate converts a date given by the user to YYYY-MM-DD. It accepts ISO dates
+// (2024-02-20, 2024/2/20, 20240220 or an RFC 3339 timestamp), today, tomorrow,
+// yesterday, weekdays (fri, next monday), offsets (+3d, 2w, 1m, 1y), a day of the
+// month (1st, 15th) and eow, eom or eoy for the end of the week, month or year.
+// An empty input returns an empty date.
+func ParseDate(input string) (string, error) {
+	return ParseDateAt(input, time.Now())
+}
+
+// ParseDateAt is like ParseDate, but counts relative dates from now
+func ParseDateAt(input string, now time.Time) (string, error) {
+	s := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	if s == "" {
+		return "", nil
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	t, ok := parseNamedDate(s, today)
+	if !ok {
+		t, ok = parseISODate(s)
+	}
+	if !ok {
+		return "", fmt.Errorf("invalid date '%s': use YYYY-MM-DD, today, tomorrow, a weekday (fri, next mon), an offset (+3d, 2w) or eom", input)
+	}
+	return t.Format(dateLayout), nil
+}
+
+// parseNamedDate parses the relative forms, counting from today
+func parseNamedDate(s string, today time.Time) (time.Time, bool) {
+	switch s {
+	case "today", "tod":
+		return today, true
+	case "tomorrow", "tom", "tmr":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "eow":
+		// Weeks end on Sunday
+		return today.AddDate(0, 0, (7-int(today.Weekday()))%7), true
+	case "eom":
+		return time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, time.UTC), true
+	case "eoy":
+		return time.Date(today.Year(), 12, 31, 0, 0, 0, 0, time.UTC), true
+	case "next week":
+		return nextWeekday(today, time.Monday, false), true
+	case "next month":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, time.UTC), true
+	case "next year":
+		return time.Date(today.Year()+1, 1, 1, 0, 0, 0, 0, time.UTC), true
+	}
+
+	if day, ok := weekdays[s]; ok {
+		return nextWeekday(today, day, false), true
+	}
+	if rest, ok := strings.CutPrefix(s, "next "); ok {
+		if day, ok := weekdays[rest]; ok {
+			return nextWeekday(today, day, true), true
+		}
+	}
+
+	if m := relativePattern.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return time.Time{}, false
+		}
+		if m[1] == "-" {
+			n = -n
+		}
+		switch m[3] {
+		case "d":
+			return today.AddDate(0, 0, n), true
+		case "w":
+			return today.AddDate(0, 0, 7*n), true
+		case "m":
+			return addMonths(today, n), true
+		case "y":
+			return addMonths(today, 12*n), true
+		}
+	}
+
+	if m := ordinalPattern.FindStringSubmatch(s); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		return nextDayOfMonth(today, day)
+	}
+
+	return time.Time{}, false
+}
+
+// parseISODate parses the locale-free absolute forms
+func parseISODate(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, strings.ToUpper(s)); err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true
+	}
+
+	m := isoPattern.FindStringSubmatch(s)
+	if m == nil {
+		m = compactPattern.FindStringSubmatch(s)
+	}
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	// Reject dates that time.Date normalized, such as 2024-02-30
+	if t.Month() != time.Month(month) || t.Day() != day {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// nextWeekday returns the first given weekday after today. With nextWeek set it returns
+// that weekday in the following Monday-based week instead, so on a Tuesday "fri" is in
+// 3 days and "next fri" in 10.
+func nextWeekday(today time.Time, day time.Weekday, nextWeek bool) time.Time {
+	if !nextWeek {
+		days := (int(day) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, days)
+	}
+
+	// Days since Monday, with Sunday as the last day of the week
+	offset := func(d time.Weekday) int { return (int(d) + 6) % 7 }
+	monday := today.AddDate(0, 0, 7-offset(today.Weekday()))
+	return monday.AddDate(0, 0, offset(day))
+}
+
+// nextDayOfMonth returns the first date on or after today falling on the given day of
+// the month, skipping months that are too short
+func nextDayOfMonth(today time.Time, day int) (time.Time, bool) {
+	if day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	for i := 0; i < 12; i++ {
+		first := time.Date(today.Year(), today.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+		t := first.AddDate(0, 0, day-1)
+		if t.Month() == first.Month() && !t.Before(today) {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// addMonths adds n months, clamping to the last day of a shorter month (Jan 31 + 1m is Feb 28)
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
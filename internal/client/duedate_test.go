@@ -0,0 +1,81 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	// A Tuesday in a leap year
+	tue := time.Date(2024, 1, 30, 15, 4, 5, 0, time.Local)
+
+	tests := []struct {
+		input string
+		now   time.Time
+		want  string
+	}{
+		{"", tue, ""},
+		{"today", tue, "2024-01-30"},
+		{"tomorrow", tue, "2024-01-31"},
+		{"tmr", tue, "2024-01-31"},
+		{"yesterday", tue, "2024-01-29"},
+		{"fri", tue, "2024-02-02"},
+		{"Friday", tue, "2024-02-02"},
+		{"tue", tue, "2024-02-06"},
+		{"next mon", tue, "2024-02-05"},
+		{"next fri", tue, "2024-02-09"},
+		{"  Next   Monday ", tue, "2024-02-05"},
+		{"+3d", tue, "2024-02-02"},
+		{"-1d", tue, "2024-01-29"},
+		{"2w", tue, "2024-02-13"},
+		{"1y", tue, "2025-01-30"},
+		{"eow", tue, "2024-02-04"},
+		{"eom", tue, "2024-01-31"},
+		{"eoy", tue, "2024-12-31"},
+		{"next week", tue, "2024-02-05"},
+		{"next month", tue, "2024-02-01"},
+		{"31st", tue, "2024-01-31"},
+		{"30th", tue, "2024-01-30"},
+		{"2024-02-20", tue, "2024-02-20"},
+		{"2024/2/20", tue, "2024-02-20"},
+		{"20240220", tue, "2024-02-20"},
+		{"2024-02-20T23:00:00Z", tue, "2024-02-20"},
+
+		// Month and year end rollover
+		{"tomorrow", time.Date(2024, 1, 31, 12, 0, 0, 0, time.Local), "2024-02-01"},
+		{"1m", time.Date(2024, 1, 31, 12, 0, 0, 0, time.Local), "2024-02-29"},
+		{"1m", time.Date(2023, 1, 31, 12, 0, 0, 0, time.Local), "2023-02-28"},
+		{"eom", time.Date(2024, 2, 10, 12, 0, 0, 0, time.Local), "2024-02-29"},
+		{"30th", time.Date(2023, 2, 1, 12, 0, 0, 0, time.Local), "2023-03-30"},
+		{"tomorrow", time.Date(2024, 12, 31, 23, 59, 0, 0, time.Local), "2025-01-01"},
+		{"+2d", time.Date(2024, 12, 31, 12, 0, 0, 0, time.Local), "2025-01-02"},
+		{"fri", time.Date(2024, 12, 30, 12, 0, 0, 0, time.Local), "2025-01-03"},
+	}
+	for _, tt := range tests {
+		got, err := ParseDateAt(tt.input, tt.now)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDateAt(%q, %s) = %q, %v, want %q", tt.input, tt.now.Format(dateLayout), got, err, tt.want)
+		}
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	now := time.Date(2024, 1, 30, 12, 0, 0, 0, time.Local)
+	for _, input := range []string{
+		"someday",
+		"next",
+		"next tomorrow",
+		"2024-02-30",
+		"2023-02-29",
+		"2024-13-01",
+		"20241301",
+		"32nd",
+		"0th",
+		"3x",
+		"+d",
+	} {
+		if got, err := ParseDateAt(input, now); err == nil {
+			t.Errorf("ParseDateAt(%q) = %q, want an error", input, got)
+		}
+	}
+}
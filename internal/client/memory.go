@@ -0,0 +1,600 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryService is an in-memory TaskService for tests and offline use.
+// It mirrors the behaviour of Client closely enough for end-to-end command tests.
+type MemoryService struct {
+	mu     sync.Mutex
+	lists  []*TaskList
+	tasks  map[string][]*Task // ordered tasks per task list ID
+	hidden map[string]bool
+	nextID int
+	// version numbers the etags of stored tasks
+	version int
+}
+
+// NewMemoryService creates an in-memory service with a single default task list
+func NewMemoryService() *MemoryService {
+	s := &MemoryService{
+		tasks:  make(map[string][]*Task),
+		hidden: make(map[string]bool),
+	}
+	s.lists = append(s.lists, &TaskList{ID: s.newID("l"), Title: "My Tasks"})
+	return s
+}
+
+// newID returns a new ID whose short form (first 8 characters) is unique
+func (s *MemoryService) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s%07d", prefix, s.nextID)
+}
+
+// touch records a change to a stored task, giving it a new etag
+func (s *MemoryService) touch(t *Task) {
+	s.version++
+	t.Etag = fmt.Sprintf("\"%d\"", s.version)
+	t.Updated = time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// list returns the task list with the given ID; @default refers to the first list
+func (s *MemoryService) list(id string) (*TaskList, error) {
+	if id == DefaultTaskList && len(s.lists) > 0 {
+		return s.lists[0], nil
+	}
+	for _, l := range s.lists {
+		if l.ID == id {
+			return l, nil
+		}
+	}
+	return nil, fmt.Errorf("task list '%s' not found", id)
+}
+
+// resolve returns the index of the task matching the (possibly short) ID in a list
+func (s *MemoryService) resolve(listID, shortID string) (int, error) {
+	idx := -1
+	for i, t := range s.tasks[listID] {
+		if t.ID == shortID {
+			return i, nil
+		}
+		if strings.HasPrefix(t.ID, shortID) {
+			if idx != -1 {
+				return -1, fmt.Errorf("task ID '%s' matches multiple tasks, please use a longer ID", shortID)
+			}
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return -1, fmt.Errorf("task '%s' not found", shortID)
+	}
+	return idx, nil
+}
+
+// output returns a copy of a stored task with its list name and position filled in
+func (s *MemoryService) output(list *TaskList, t *Task) *Task {
+	out := *t
+	out.TaskListID = list.ID
+	out.TaskListName = list.Title
+
+	position := 0
+	for _, other := range s.tasks[list.ID] {
+		if other.ID == t.ID {
+			break
+		}
+		if other.Parent == t.Parent {
+			position++
+		}
+	}
+	out.Position = fmt.Sprintf("%020d", position)
+	return &out
+}
+
+// matches reports whether a stored task passes the filter
+func (s *MemoryService) matches(t *Task, filter TaskFilter) bool {
+	completed := t.Status == StatusCompleted
+	if !filter.showCompleted() {
+		return !completed
+	}
+	if filter.CompletedOnly && !completed {
+		return false
+	}
+	if s.hidden[t.ID] && filter.VisibleOnly {
+		return false
+	}
+	if completed {
+		day := ParseDueDate(t.Completed)
+		if filter.CompletedMin != "" && day < filter.CompletedMin {
+			return false
+		}
+		if filter.CompletedMax != "" && day > filter.CompletedMax {
+			return false
+		}
+	}
+	return true
+}
+
+// GetTaskLists returns all task lists
+func (s *MemoryService) GetTaskLists(ctx context.Context) ([]*TaskList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lists []*TaskList
+	for _, l := range s.lists {
+		lists = append(lists, &TaskList{ID: l.ID, Title: l.Title})
+	}
+	return lists, nil
+}
+
+// ResolveTaskListID resolves a task list name to its ID
+func (s *MemoryService) ResolveTaskListID(ctx context.Context, name string) (string, error) {
+	if name == DefaultTaskList || name == "" {
+		return DefaultTaskList, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, l := range s.lists {
+		if l.Title == name {
+			return l.ID, nil
+		}
+	}
+	return "", fmt.Errorf("task list '%s' not found", name)
+}
+
+// GetTaskListName returns the task list name for display
+func (s *MemoryService) GetTaskListName(ctx context.Context, id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(id)
+	if err != nil {
+		return "", err
+	}
+	return list.Title, nil
+}
+
+// GetTaskListStats returns the number of open and completed tasks in a task list
+func (s *MemoryService) GetTaskListStats(ctx context.Context, taskListID string) (*TaskListStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &TaskListStats{TaskList: &TaskList{ID: list.ID, Title: list.Title}}
+	for _, t := range s.tasks[list.ID] {
+		if t.Status == StatusCompleted {
+			stats.CompletedTasks++
+		} else {
+			stats.OpenTasks++
+		}
+	}
+	return stats, nil
+}
+
+// CreateTaskList creates a new task list
+func (s *MemoryService) CreateTaskList(ctx context.Context, title string) (*TaskList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := &TaskList{ID: s.newID("l"), Title: title}
+	s.lists = append(s.lists, list)
+	return &TaskList{ID: list.ID, Title: list.Title}, nil
+}
+
+// RenameTaskList changes the title of a task list
+func (s *MemoryService) RenameTaskList(ctx context.Context, taskListID, title string) (*TaskList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return nil, err
+	}
+	list.Title = title
+	return &TaskList{ID: list.ID, Title: list.Title}, nil
+}
+
+// DeleteTaskList deletes a task list and all of its tasks
+func (s *MemoryService) DeleteTaskList(ctx context.Context, taskListID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return err
+	}
+	for i, l := range s.lists {
+		if l == list {
+			s.lists = append(s.lists[:i], s.lists[i+1:]...)
+			break
+		}
+	}
+	delete(s.tasks, list.ID)
+	return nil
+}
+
+// ListAllTasks returns all incomplete tasks from all task lists
+func (s *MemoryService) ListAllTasks(ctx context.Context) ([]*Task, error) {
+	return s.ListAllTasksFiltered(ctx, TaskFilter{})
+}
+
+// ListAllTasksFiltered returns tasks matching filter from all task lists
+func (s *MemoryService) ListAllTasksFiltered(ctx context.Context, filter TaskFilter) ([]*Task, error) {
+	lists, _ := s.GetTaskLists(ctx)
+
+	var allTasks []*Task
+	for _, list := range lists {
+		tasks, err := s.ListTasksFiltered(ctx, list.ID, filter)
+		if err != nil {
+			return nil, err
+		}
+		allTasks = append(allTasks, tasks...)
+	}
+	return allTasks, nil
+}
+
+// ListTasks returns all incomplete tasks in the specified task list
+func (s *MemoryService) ListTasks(ctx context.Context, taskListID string) ([]*Task, error) {
+	return s.ListTasksFiltered(ctx, taskListID, TaskFilter{})
+}
+
+// ListTasksFiltered returns tasks matching filter in the specified task list
+func (s *MemoryService) ListTasksFiltered(ctx context.Context, taskListID string, filter TaskFilter) ([]*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []*Task
+	for _, t := range s.tasks[list.ID] {
+		if s.matches(t, filter) {
+			tasks = append(tasks, s.output(list, t))
+		}
+	}
+	return tasks, nil
+}
+
+// GetTask returns a task by ID from a specific task list
+func (s *MemoryService) GetTask(ctx context.Context, taskListID, taskID string) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := s.resolve(list.ID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return s.output(list, s.tasks[list.ID][idx]), nil
+}
+
+// FindTask searches for a task by ID across all task lists
+func (s *MemoryService) FindTask(ctx context.Context, taskID string) (*Task, error) {
+	lists, _ := s.GetTaskLists(ctx)
+	for _, list := range lists {
+		task, err := s.GetTask(ctx, list.ID, taskID)
+		if err == nil {
+			return task, nil
+		}
+	}
+	return nil, fmt.Errorf("task '%s' not found", taskID)
+}
+
+// ResolveTaskID resolves a short task ID to a full ID
+func (s *MemoryService) ResolveTaskID(ctx context.Context, taskListID, shortID string) (string, error) {
+	task, err := s.GetTask(ctx, taskListID, shortID)
+	if err != nil {
+		return "", err
+	}
+	return task.ID, nil
+}
+
+// CreateTask creates a new task
+func (s *MemoryService) CreateTask(ctx context.Context, taskListID string, task *Task) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return nil, err
+	}
+
+	parentID := ""
+	if task.Parent != "" {
+		idx, err := s.resolve(list.ID, task.Parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve parent task: %w", err)
+		}
+		parentID = s.tasks[list.ID][idx].ID
+	}
+
+	// Like the API, new tasks come first among their siblings
+	created := s.insertTask(list.ID, task, parentID, nil)
+	return s.output(list, created), nil
+}
+
+// CreateTasks creates a tree of tasks in order, keeping their hierarchy
+func (s *MemoryService) CreateTasks(ctx context.Context, taskListID string, roots []*TaskNode) ([]*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return nil, nil
+	}
+
+	rootParent := ""
+	if p := roots[0].Task.Parent; p != "" {
+		idx, err := s.resolve(list.ID, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve parent task: %w", err)
+		}
+		rootParent = s.tasks[list.ID][idx].ID
+	}
+
+	var result []*Task
+	var create func(parentID string, nodes []*TaskNode)
+	create = func(parentID string, nodes []*TaskNode) {
+		var previous *Task
+		for _, node := range nodes {
+			created := s.insertTask(list.ID, node.Task, parentID, previous)
+			result = append(result, s.output(list, created))
+			previous = created
+
+			create(created.ID, node.Subtasks)
+		}
+	}
+	create(rootParent, roots)
+
+	return result, nil
+}
+
+// insertTask stores a new task with the fields of task under parentID, right after
+// previous or first among its siblings if previous is nil. CreateTask and CreateTasks
+// both insert through it.
+func (s *MemoryService) insertTask(listID string, task *Task, parentID string, previous *Task) *Task {
+	created := &Task{
+		ID:     s.newID("t"),
+		Title:  task.Title,
+		Notes:  task.Notes,
+		Due:    task.Due,
+		Status: StatusNeedsAction,
+		Parent: parentID,
+	}
+	if task.Status == StatusCompleted {
+		created.Status = StatusCompleted
+		created.Completed = time.Now().UTC().Format(time.RFC3339)
+	}
+	s.touch(created)
+	s.insertAfter(listID, previous, created)
+	return created
+}
+
+// insertAfter stores a task, followed by its subtasks, right after previous and its
+// subtasks, or first among its siblings if previous is nil
+func (s *MemoryService) insertAfter(listID string, previous *Task, task *Task, subtasks ...*Task) {
+	target := s.tasks[listID]
+	at := len(target)
+	for i, t := range target {
+		if previous == nil {
+			if t.Parent == task.Parent {
+				at = i
+				break
+			}
+			if t.ID == task.Parent {
+				at = i + 1 // the parent has no other subtasks
+			}
+		} else if t == previous || t.Parent == previous.ID {
+			at = i + 1
+		}
+	}
+
+	inserted := append([]*Task{}, target[:at]...)
+	inserted = append(inserted, task)
+	inserted = append(inserted, subtasks...)
+	s.tasks[listID] = append(inserted, target[at:]...)
+}
+
+// PatchTask changes only the given fields of a task
+func (s *MemoryService) PatchTask(ctx context.Context, taskListID, taskID string, changes TaskChanges) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := s.resolve(list.ID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := s.tasks[list.ID][idx]
+	if changes.Etag != "" && existing.Etag != changes.Etag {
+		return nil, &ConflictError{Current: s.output(list, existing)}
+	}
+	if changes.Title != nil {
+		existing.Title = *changes.Title
+	}
+	if changes.Notes != nil {
+		existing.Notes = *changes.Notes
+	}
+	if changes.Due != nil {
+		existing.Due = *changes.Due
+	}
+	if changes.Status != nil {
+		s.setStatus(existing, *changes.Status == StatusCompleted)
+	}
+	s.touch(existing)
+
+	return s.output(list, existing), nil
+}
+
+// setStatus updates the status and completion timestamp of a stored task
+func (s *MemoryService) setStatus(t *Task, completed bool) {
+	if completed {
+		if t.Status != StatusCompleted {
+			t.Completed = time.Now().UTC().Format(time.RFC3339)
+		}
+		t.Status = StatusCompleted
+		return
+	}
+	t.Status = StatusNeedsAction
+	t.Completed = ""
+	delete(s.hidden, t.ID)
+}
+
+// MoveTask moves a task to another position, parent or task list
+func (s *MemoryService) MoveTask(ctx context.Context, taskListID, taskID string, opts MoveOptions) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src, err := s.list(taskListID)
+	if err != nil {
+		return nil, err
+	}
+	dest := src
+	if opts.DestinationListID != "" {
+		dest, err = s.list(opts.DestinationListID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	idx, err := s.resolve(src.ID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	task := s.tasks[src.ID][idx]
+
+	// Resolve everything before changing the lists, so a failed move changes nothing
+	parentID := ""
+	if opts.Parent != "" {
+		pidx, err := s.resolve(dest.ID, opts.Parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve parent task: %w", err)
+		}
+		parentID = s.tasks[dest.ID][pidx].ID
+		if parentID == task.ID {
+			return nil, fmt.Errorf("task '%s' cannot be its own parent", task.Title)
+		}
+	}
+	var previous *Task
+	if opts.Previous != "" {
+		pidx, err := s.resolve(dest.ID, opts.Previous)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve previous task: %w", err)
+		}
+		previous = s.tasks[dest.ID][pidx]
+		if previous == task || previous.Parent != parentID {
+			return nil, fmt.Errorf("previous task '%s' is not a sibling of '%s'", previous.Title, task.Title)
+		}
+	}
+
+	// Take the task and its subtasks out of the source list
+	var subtasks, remaining []*Task
+	for _, t := range s.tasks[src.ID] {
+		switch {
+		case t == task:
+		case t.Parent == task.ID:
+			subtasks = append(subtasks, t)
+		default:
+			remaining = append(remaining, t)
+		}
+	}
+	s.tasks[src.ID] = remaining
+
+	task.Parent = parentID
+	s.insertAfter(dest.ID, previous, task, subtasks...)
+	s.touch(task)
+
+	return s.output(dest, task), nil
+}
+
+// CompleteTask marks a task as completed
+func (s *MemoryService) CompleteTask(ctx context.Context, taskListID, taskID string) (*Task, error) {
+	return s.changeStatus(taskListID, taskID, true)
+}
+
+// UncompleteTask marks a completed task as needing action again
+func (s *MemoryService) UncompleteTask(ctx context.Context, taskListID, taskID string) (*Task, error) {
+	return s.changeStatus(taskListID, taskID, false)
+}
+
+func (s *MemoryService) changeStatus(taskListID, taskID string, completed bool) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := s.resolve(list.ID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	task := s.tasks[list.ID][idx]
+	s.setStatus(task, completed)
+	s.touch(task)
+	return s.output(list, task), nil
+}
+
+// ClearCompleted hides all completed tasks in the specified task list
+func (s *MemoryService) ClearCompleted(ctx context.Context, taskListID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return err
+	}
+	for _, t := range s.tasks[list.ID] {
+		if t.Status == StatusCompleted {
+			s.hidden[t.ID] = true
+		}
+	}
+	return nil
+}
+
+// DeleteTask deletes a task and its subtasks
+func (s *MemoryService) DeleteTask(ctx context.Context, taskListID, taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.list(taskListID)
+	if err != nil {
+		return err
+	}
+	idx, err := s.resolve(list.ID, taskID)
+	if err != nil {
+		return err
+	}
+
+	fullID := s.tasks[list.ID][idx].ID
+	var remaining []*Task
+	for _, t := range s.tasks[list.ID] {
+		if t.ID != fullID && t.Parent != fullID {
+			remaining = append(remaining, t)
+		}
+	}
+	s.tasks[list.ID] = remaining
+	return nil
+}
@@ -0,0 +1,237 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+// Errors returned (wrapped) by API calls, for use with errors.Is
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrQuotaExceeded = errors.New("API rate limit or quota exceeded, please try again later")
+	ErrUnauthorized  = errors.New("authentication failed, please sign in again")
+	ErrForbidden     = errors.New("access denied")
+)
+
+// RetryOptions controls how failed API requests are retried
+type RetryOptions struct {
+	// MaxAttempts is the total number of attempts per request, including the first one
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles on every retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, including delays requested via Retry-After
+	MaxDelay time.Duration
+}
+
+// DefaultRetryOptions returns the retry settings used unless overridden
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{
+		MaxAttempts: 5,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// rateLimitReasons are the googleapi error reasons Google uses for 403 rate limiting
+var rateLimitReasons = map[string]bool{
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+	"quotaExceeded":         true,
+	"dailyLimitExceeded":    true,
+}
+
+// retryTransport retries requests that failed with a 429 or 403 rate limit response,
+// and idempotent requests that failed with a network error or 5xx, using jittered
+// exponential backoff and honouring Retry-After
+type retryTransport struct {
+	base http.RoundTripper
+	opts RetryOptions
+}
+
+func newRetryTransport(base http.RoundTripper, opts RetryOptions) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	return &retryTransport{base: base, opts: opts}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests with a body can only be retried if the body can be recreated
+	replayable := req.Body == nil || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= t.opts.MaxAttempts || !replayable || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if d > t.opts.MaxDelay {
+				d = t.opts.MaxDelay
+			}
+			return d
+		}
+	}
+
+	d := t.opts.BaseDelay << (attempt - 1)
+	if d <= 0 || d > t.opts.MaxDelay {
+		d = t.opts.MaxDelay
+	}
+	// Full jitter between half and the whole delay
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// shouldRetry reports whether a response or transport error is worth retrying.
+// Rate limited requests were not processed, so they are always retried. After a
+// network error or 5xx the server may have processed the request anyway, so only
+// requests that can safely be repeated are retried; a repeated insert or move
+// could otherwise create a duplicate task.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// Rejected credentials and cancellation are final; other transport errors are usually transient
+		var rerr *oauth2.RetrieveError
+		if errors.As(err, &rerr) {
+			// The token could not be refreshed, so the request itself was never sent
+			return rerr.Response != nil && rerr.Response.StatusCode >= 500
+		}
+		return req.Context().Err() == nil && isIdempotent(req.Method)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500:
+		return isIdempotent(req.Method)
+	case resp.StatusCode == http.StatusForbidden:
+		return isRateLimitResponse(resp)
+	}
+	return false
+}
+
+// isIdempotent reports whether sending a request twice has the same effect as sending
+// it once. This holds for the PATCH requests gt sends, which set fields to fixed values.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRateLimitResponse checks a 403 response body for a rate limit reason,
+// leaving the body readable for the caller
+func isRateLimitResponse(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	err = googleapi.CheckResponse(&http.Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	})
+	var gerr *googleapi.Error
+	return errors.As(err, &gerr) && isRateLimitError(gerr)
+}
+
+// isRateLimitError reports whether a googleapi error carries a rate limit reason
+func isRateLimitError(gerr *googleapi.Error) bool {
+	for _, item := range gerr.Errors {
+		if rateLimitReasons[item.Reason] {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		d := time.Until(at)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// apiError classifies an error returned by the Tasks API so callers can tell quota
+// exhaustion, authentication problems and missing resources apart
+func apiError(err error) error {
+	var rerr *oauth2.RetrieveError
+	if errors.As(err, &rerr) {
+		if rerr.ErrorCode == "" {
+			return ErrUnauthorized
+		}
+		return fmt.Errorf("%w (%s)", ErrUnauthorized, rerr.ErrorCode)
+	}
+
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return err
+	}
+
+	switch {
+	case gerr.Code == http.StatusTooManyRequests,
+		gerr.Code == http.StatusForbidden && isRateLimitError(gerr):
+		return fmt.Errorf("%w (%s)", ErrQuotaExceeded, gerr.Message)
+	case gerr.Code == http.StatusUnauthorized:
+		return fmt.Errorf("%w (%s)", ErrUnauthorized, gerr.Message)
+	case gerr.Code == http.StatusForbidden:
+		return fmt.Errorf("%w (%s)", ErrForbidden, gerr.Message)
+	case gerr.Code == http.StatusNotFound:
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRetryOptions retries quickly so tests do not wait
+var testRetryOptions = RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+const rateLimitBody = `{"error": {"code": 403, "message": "Rate Limit Exceeded", "errors": [{"reason": "rateLimitExceeded"}]}}`
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		body     string
+		attempts int32
+	}{
+		{"GET after 503", http.MethodGet, http.StatusServiceUnavailable, "", 3},
+		{"PATCH after 500", http.MethodPatch, http.StatusInternalServerError, "", 3},
+		{"DELETE after 502", http.MethodDelete, http.StatusBadGateway, "", 3},
+		{"POST after 503", http.MethodPost, http.StatusServiceUnavailable, "", 1},
+		{"POST after 429", http.MethodPost, http.StatusTooManyRequests, "", 3},
+		{"POST after 403 rate limit", http.MethodPost, http.StatusForbidden, rateLimitBody, 3},
+		{"GET after 403 forbidden", http.MethodGet, http.StatusForbidden, `{"error": {"code": 403, "message": "Forbidden"}}`, 1},
+		{"GET after 404", http.MethodGet, http.StatusNotFound, "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				if body, _ := io.ReadAll(r.Body); r.Method == http.MethodPost && string(body) != `{"title":"x"}` {
+					t.Errorf("attempt %d sent body %q", attempts.Load(), body)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			var body io.Reader
+			if tt.method == http.MethodPost {
+				body = strings.NewReader(`{"title":"x"}`)
+			}
+			req, err := http.NewRequest(tt.method, server.URL, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			httpClient := &http.Client{Transport: newRetryTransport(nil, testRetryOptions)}
+			resp, err := httpClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := attempts.Load(); got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestRetryTransportSucceedsAfterRetry(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Transport: newRetryTransport(nil, testRetryOptions)}
+	resp, err := httpClient.Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 2 {
+		t.Errorf("status = %d after %d attempts, want 200 after 2", resp.StatusCode, attempts.Load())
+	}
+}
+
+// failingTransport fails every request with a network error
+type failingTransport struct {
+	attempts int
+}
+
+func (f *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.attempts++
+	return nil, errors.New("connection reset by peer")
+}
+
+func TestRetryTransportNetworkErrors(t *testing.T) {
+	for method, want := range map[string]int{http.MethodGet: 3, http.MethodPost: 1} {
+		base := &failingTransport{}
+		httpClient := &http.Client{Transport: newRetryTransport(base, testRetryOptions)}
+
+		req, err := http.NewRequest(method, "http://tasks.invalid/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := httpClient.Do(req); err == nil {
+			t.Errorf("%s: request succeeded", method)
+		}
+		if base.attempts != want {
+			t.Errorf("%s: attempts = %d, want %d", method, base.attempts, want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"7", 7 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package client
+
+import "context"
+
+// TaskService is the set of task operations used by commands.
+// Client implements it against the Google Tasks API; MemoryService keeps everything in memory.
+type TaskService interface {
+	// Task lists
+	GetTaskLists(ctx context.Context) ([]*TaskList, error)
+	ResolveTaskListID(ctx context.Context, name string) (string, error)
+	GetTaskListName(ctx context.Context, id string) (string, error)
+	GetTaskListStats(ctx context.Context, taskListID string) (*TaskListStats, error)
+	CreateTaskList(ctx context.Context, title string) (*TaskList, error)
+	RenameTaskList(ctx context.Context, taskListID, title string) (*TaskList, error)
+	DeleteTaskList(ctx context.Context, taskListID string) error
+
+	// Listing and lookup
+	ListAllTasks(ctx context.Context) ([]*Task, error)
+	ListAllTasksFiltered(ctx context.Context, filter TaskFilter) ([]*Task, error)
+	ListTasks(ctx context.Context, taskListID string) ([]*Task, error)
+	ListTasksFiltered(ctx context.Context, taskListID string, filter TaskFilter) ([]*Task, error)
+	GetTask(ctx context.Context, taskListID, taskID string) (*Task, error)
+	FindTask(ctx context.Context, taskID string) (*Task, error)
+	ResolveTaskID(ctx context.Context, taskListID, shortID string) (string, error)
+
+	// Modification
+	CreateTask(ctx context.Context, taskListID string, task *Task) (*Task, error)
+	CreateTasks(ctx context.Context, taskListID string, roots []*TaskNode) ([]*Task, error)
+	PatchTask(ctx context.Context, taskListID, taskID string, changes TaskChanges) (*Task, error)
+	MoveTask(ctx context.Context, taskListID, taskID string, opts MoveOptions) (*Task, error)
+	CompleteTask(ctx context.Context, taskListID, taskID string) (*Task, error)
+	UncompleteTask(ctx context.Context, taskListID, taskID string) (*Task, error)
+	ClearCompleted(ctx context.Context, taskListID string) error
+	DeleteTask(ctx context.Context, taskListID, taskID string) error
+}
+
+var (
+	_ TaskService = (*Client)(nil)
+	_ TaskService = (*MemoryService)(nil)
+)
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/t3yamoto/gt/internal/cache"
+	"google.golang.org/api/tasks/v1"
+)
+
+// TaskListStats holds task counts for a task list
+type TaskListStats struct {
+	TaskList       *TaskList
+	OpenTasks      int
+	CompletedTasks int
+}
+
+// CreateTaskList creates a new task list
+func (c *Client) CreateTaskList(ctx context.Context, title string) (*TaskList, error) {
+	tl, err := c.service.Tasklists.Insert(&tasks.TaskList{Title: title}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task list: %w", apiError(err))
+	}
+
+	created := &TaskList{ID: tl.Id, Title: tl.Title}
+	if c.cache != nil {
+		c.cache.AddTaskList(cache.TaskListCache{ID: created.ID, Title: created.Title})
+	}
+	return created, nil
+}
+
+// RenameTaskList changes the title of a task list
+func (c *Client) RenameTaskList(ctx context.Context, taskListID, title string) (*TaskList, error) {
+	tl, err := c.service.Tasklists.Patch(taskListID, &tasks.TaskList{Title: title}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename task list: %w", apiError(err))
+	}
+
+	renamed := &TaskList{ID: tl.Id, Title: tl.Title}
+	if c.cache != nil {
+		c.cache.UpdateTaskList(cache.TaskListCache{ID: renamed.ID, Title: renamed.Title})
+	}
+	return renamed, nil
+}
+
+// DeleteTaskList deletes a task list and all of its tasks
+func (c *Client) DeleteTaskList(ctx context.Context, taskListID string) error {
+	if err := c.service.Tasklists.Delete(taskListID).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to delete task list: %w", apiError(err))
+	}
+
+	if c.cache != nil {
+		c.cache.RemoveTaskList(taskListID)
+	}
+	return nil
+}
+
+// GetTaskListStats returns the number of open and completed tasks in a task list
+func (c *Client) GetTaskListStats(ctx context.Context, taskListID string) (*TaskListStats, error) {
+	tl, err := c.service.Tasklists.Get(taskListID).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task list: %w", apiError(err))
+	}
+
+	stats := &TaskListStats{
+		TaskList: &TaskList{ID: tl.Id, Title: tl.Title},
+	}
+
+	call := c.service.Tasks.List(taskListID).
+		ShowCompleted(true).
+		ShowHidden(true)
+
+	_, err = c.eachTaskPage(ctx, call, 0, func(resp *tasks.Tasks) error {
+		for _, t := range resp.Items {
+			if t.Status == StatusCompleted {
+				stats.CompletedTasks++
+			} else {
+				stats.OpenTasks++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", apiError(err))
+	}
+	return stats, nil
+}
@@ -2,11 +2,17 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/t3yamoto/gt/internal/auth"
 	"github.com/t3yamoto/gt/internal/cache"
+	"github.com/t3yamoto/gt/internal/profile"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	"google.golang.org/api/tasks/v1"
 )
@@ -19,8 +25,13 @@ type Task struct {
 	Due          string
 	Status       string
 	Completed    string
+	Parent       string
+	Position     string
+	Etag         string // version of the task, which changes with every update
+	Updated      string // time of the last change (RFC 3339)
 	TaskListID   string
 	TaskListName string
+	Profile      string // set when listing tasks across several profiles
 }
 
 // TaskList represents a task list
@@ -29,27 +40,143 @@ type TaskList struct {
 	Title string
 }
 
+// PageOptions controls how list requests are paged
+type PageOptions struct {
+	// MaxResults is the page size requested from the API
+	MaxResults int64
+}
+
+// TaskFilter selects which tasks list requests return
+type TaskFilter struct {
+	// IncludeCompleted returns completed tasks alongside incomplete ones
+	IncludeCompleted bool
+	// CompletedOnly returns only completed tasks
+	CompletedOnly bool
+	// CompletedMin and CompletedMax limit completed tasks to a date range (YYYY-MM-DD, inclusive)
+	CompletedMin string
+	CompletedMax string
+	// VisibleOnly excludes completed tasks already hidden by a clear
+	VisibleOnly bool
+	// MaxPages caps the number of pages fetched per list, for display; 0 means no
+	// limit. Capped results are never cached, and lists cut short are reported with
+	// a *TruncatedError returned along with the tasks.
+	MaxPages int
+}
+
+// showCompleted reports whether the filter asks for completed tasks
+func (f TaskFilter) showCompleted() bool {
+	return f.IncludeCompleted || f.CompletedOnly
+}
+
+// errStopPaging stops a page iteration early without reporting an error
+var errStopPaging = errors.New("stop paging")
+
 // Client wraps the Google Tasks API service
 type Client struct {
 	service *tasks.Service
 	cache   *cache.Cache
+	paging  PageOptions
+	workers int
+}
+
+// clientConfig holds the settings applied by Options
+type clientConfig struct {
+	profile profile.Profile
+	baseURL string
+	noCache bool
+	retry   RetryOptions
+}
+
+// Option configures a Client
+type Option func(*clientConfig)
+
+// WithBaseURL points the client at a different Tasks REST API endpoint, such as a local
+// stand-in server in tests. Requests to it are sent without authentication.
+func WithBaseURL(url string) Option {
+	return func(cfg *clientConfig) {
+		cfg.baseURL = url
+	}
+}
+
+// WithProfile uses the given profile's token and cache instead of the current profile's
+func WithProfile(p profile.Profile) Option {
+	return func(cfg *clientConfig) {
+		cfg.profile = p
+	}
+}
+
+// WithRetry overrides how failed API requests are retried
+func WithRetry(opts RetryOptions) Option {
+	return func(cfg *clientConfig) {
+		cfg.retry = opts
+	}
+}
+
+// WithoutCache disables the file-based cache
+func WithoutCache() Option {
+	return func(cfg *clientConfig) {
+		cfg.noCache = true
+	}
 }
 
 // NewClient creates a new Tasks API client
-func NewClient(ctx context.Context) (*Client, error) {
-	httpClient, err := auth.GetClient(ctx)
-	if err != nil {
-		return nil, err
+func NewClient(ctx context.Context, opts ...Option) (*Client, error) {
+	cfg := clientConfig{profile: profile.Current(), retry: DefaultRetryOptions()}
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
-	service, err := tasks.NewService(ctx, option.WithHTTPClient(httpClient))
+	var serviceOpts []option.ClientOption
+	if cfg.baseURL != "" {
+		baseURL := cfg.baseURL
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		serviceOpts = append(serviceOpts,
+			option.WithHTTPClient(&http.Client{Transport: newRetryTransport(nil, cfg.retry)}),
+			option.WithEndpoint(baseURL))
+	} else {
+		httpClient, err := auth.GetClientForProfile(ctx, cfg.profile)
+		if err != nil {
+			return nil, err
+		}
+		retrying := *httpClient
+		retrying.Transport = newRetryTransport(httpClient.Transport, cfg.retry)
+		serviceOpts = append(serviceOpts, option.WithHTTPClient(&retrying))
+	}
+
+	service, err := tasks.NewService(ctx, serviceOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize Tasks service: %w", err)
 	}
 
-	c, _ := cache.New() // Ignore cache initialization errors
+	var c *cache.Cache
+	if !cfg.noCache {
+		c, _ = cache.NewForProfile(cfg.profile) // Ignore cache initialization errors
+	}
+
+	return &Client{
+		service: service,
+		cache:   c,
+		paging:  PageOptions{MaxResults: DefaultMaxResults},
+		workers: DefaultWorkers,
+	}, nil
+}
+
+// SetPageOptions overrides the paging behaviour of list requests
+func (c *Client) SetPageOptions(opts PageOptions) {
+	if opts.MaxResults <= 0 {
+		opts.MaxResults = DefaultMaxResults
+	}
+	c.paging = opts
+}
 
-	return &Client{service: service, cache: c}, nil
+// SetWorkers sets the number of task lists fetched concurrently
+func (c *Client) SetWorkers(n int) {
+	if n <= 0 {
+		n = DefaultWorkers
+	}
+	c.workers = n
 }
 
 // GetTaskLists returns all task lists
@@ -68,17 +195,20 @@ func (c *Client) GetTaskLists(ctx context.Context) ([]*TaskList, error) {
 		}
 	}
 
-	resp, err := c.service.Tasklists.List().Context(ctx).Do()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get task lists: %w", err)
-	}
-
 	var lists []*TaskList
-	for _, tl := range resp.Items {
-		lists = append(lists, &TaskList{
-			ID:    tl.Id,
-			Title: tl.Title,
+	err := c.service.Tasklists.List().
+		MaxResults(c.paging.MaxResults).
+		Pages(ctx, func(resp *tasks.TaskLists) error {
+			for _, tl := range resp.Items {
+				lists = append(lists, &TaskList{
+					ID:    tl.Id,
+					Title: tl.Title,
+				})
+			}
+			return nil
 		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task lists: %w", apiError(err))
 	}
 	return lists, nil
 }
@@ -127,22 +257,17 @@ func (c *Client) GetTaskListName(ctx context.Context, id string) (string, error)
 
 	tl, err := c.service.Tasklists.Get(id).Context(ctx).Do()
 	if err != nil {
-		return "", fmt.Errorf("failed to get task list: %w", err)
+		return "", fmt.Errorf("failed to get task list: %w", apiError(err))
 	}
 	return tl.Title, nil
 }
 
-// ListAllTasks returns all incomplete tasks from all task lists
+// ListAllTasks returns all incomplete tasks from all task lists.
+// If some lists fail to load, the tasks of the other lists are returned along with the error.
 func (c *Client) ListAllTasks(ctx context.Context) ([]*Task, error) {
 	// Try cache first
-	if c.cache != nil {
-		if cached := c.cache.Load(); cached != nil && len(cached.Tasks) > 0 {
-			var tasks []*Task
-			for _, t := range cached.Tasks {
-				tasks = append(tasks, taskFromCache(t))
-			}
-			return tasks, nil
-		}
+	if tasks := c.cachedTasks(); tasks != nil {
+		return tasks, nil
 	}
 
 	lists, err := c.GetTaskLists(ctx)
@@ -150,44 +275,286 @@ func (c *Client) ListAllTasks(ctx context.Context) ([]*Task, error) {
 		return nil, err
 	}
 
-	var allTasks []*Task
-	for _, list := range lists {
-		tasks, err := c.listTasksFromList(ctx, list.ID, list.Title)
-		if err != nil {
-			return nil, err
-		}
-		allTasks = append(allTasks, tasks...)
+	allTasks, syncedAt, err := c.syncTasks(ctx, lists)
+	if err != nil {
+		// Don't cache an incomplete snapshot
+		return allTasks, err
 	}
 
 	// Save to cache
-	c.saveToCache(lists, allTasks)
+	c.saveToCache(lists, allTasks, syncedAt)
 
 	return allTasks, nil
 }
 
+// ListAllTasksFiltered returns tasks matching filter from all task lists.
+// Completed tasks are not cached, so filters including them always hit the API.
+func (c *Client) ListAllTasksFiltered(ctx context.Context, filter TaskFilter) ([]*Task, error) {
+	if !filter.showCompleted() {
+		if filter.MaxPages == 0 {
+			return c.ListAllTasks(ctx)
+		}
+		// The cache holds every task, so there is nothing to cap
+		if tasks := c.cachedTasks(); tasks != nil {
+			return tasks, nil
+		}
+	}
+
+	lists, err := c.GetTaskLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.listTasksFromLists(ctx, lists, filter)
+}
+
 // ListTasks returns all incomplete tasks in the specified task list
 func (c *Client) ListTasks(ctx context.Context, taskListID string) ([]*Task, error) {
+	return c.ListTasksFiltered(ctx, taskListID, TaskFilter{})
+}
+
+// ListTasksFiltered returns tasks matching filter in the specified task list
+func (c *Client) ListTasksFiltered(ctx context.Context, taskListID string, filter TaskFilter) ([]*Task, error) {
 	listName, _ := c.GetTaskListName(ctx, taskListID)
-	return c.listTasksFromList(ctx, taskListID, listName)
+	return c.listTasksFromList(ctx, taskListID, listName, filter)
+}
+
+// listTasksFromLists fetches the tasks of several lists concurrently.
+// Tasks are returned in list order; errors of individual lists are joined and
+// returned along with the tasks of the lists that succeeded.
+func (c *Client) listTasksFromLists(ctx context.Context, lists []*TaskList, filter TaskFilter) ([]*Task, error) {
+	results := make([][]*Task, len(lists))
+	err := c.forEachList(ctx, lists, func(i int, list *TaskList) error {
+		tasks, err := c.listTasksFromList(ctx, list.ID, list.Title, filter)
+		results[i] = tasks
+		return err
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var allTasks []*Task
+	for _, tasks := range results {
+		allTasks = append(allTasks, tasks...)
+	}
+	return allTasks, err
+}
+
+// syncTasks fetches the incomplete tasks of all lists. Lists with a recent sync watermark
+// in the (possibly expired) cache only fetch changes since then and merge them into the
+// cached tasks; other lists are fetched in full. It returns the new watermarks per list.
+func (c *Client) syncTasks(ctx context.Context, lists []*TaskList) ([]*Task, map[string]time.Time, error) {
+	var stale *cache.CacheData
+	if c.cache != nil {
+		stale = c.cache.LoadStale()
+	}
+
+	cachedByList := make(map[string][]*Task)
+	var watermarks map[string]time.Time
+	if stale != nil {
+		for _, t := range stale.Tasks {
+			cachedByList[t.TaskListID] = append(cachedByList[t.TaskListID], taskFromCache(t))
+		}
+		watermarks = stale.SyncedAt
+	}
+
+	results := make([][]*Task, len(lists))
+	syncTimes := make([]time.Time, len(lists))
+	err := c.forEachList(ctx, lists, func(i int, list *TaskList) error {
+		start := time.Now().Add(-syncSkew)
+
+		var tasks []*Task
+		var err error
+		if since, ok := watermarks[list.ID]; ok && time.Since(since) < maxSyncAge {
+			tasks, err = c.syncTasksFromList(ctx, list, since, cachedByList[list.ID])
+		} else {
+			tasks, err = c.listTasksFromList(ctx, list.ID, list.Title, TaskFilter{})
+		}
+		if err != nil {
+			return err
+		}
+
+		results[i] = tasks
+		syncTimes[i] = start
+		return nil
+	})
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	var allTasks []*Task
+	syncedAt := make(map[string]time.Time, len(lists))
+	for i, list := range lists {
+		allTasks = append(allTasks, results[i]...)
+		syncedAt[list.ID] = syncTimes[i]
+	}
+	return allTasks, syncedAt, err
 }
 
-func (c *Client) listTasksFromList(ctx context.Context, taskListID, taskListName string) ([]*Task, error) {
-	resp, err := c.service.Tasks.List(taskListID).
-		ShowCompleted(false).
-		ShowHidden(false).
-		Context(ctx).
-		Do()
+// syncTasksFromList fetches the tasks of a list changed since the given time, including
+// deleted, hidden and completed ones, and merges them into the cached incomplete tasks
+func (c *Client) syncTasksFromList(ctx context.Context, list *TaskList, since time.Time, cached []*Task) ([]*Task, error) {
+	call := c.service.Tasks.List(list.ID).
+		UpdatedMin(since.UTC().Format(time.RFC3339)).
+		ShowCompleted(true).
+		ShowDeleted(true).
+		ShowHidden(true)
+
+	merged := make([]*Task, 0, len(cached))
+	index := make(map[string]int, len(cached))
+	for _, t := range cached {
+		t.TaskListName = list.Title
+		index[t.ID] = len(merged)
+		merged = append(merged, t)
+	}
+
+	removed := make(map[string]bool)
+	_, err := c.eachTaskPage(ctx, call, 0, func(resp *tasks.Tasks) error {
+		for _, t := range resp.Items {
+			// Only incomplete, visible tasks are cached
+			if t.Deleted || t.Hidden || t.Status == StatusCompleted {
+				removed[t.Id] = true
+				continue
+			}
+			delete(removed, t.Id)
+
+			updated := convertTask(t, list.ID, list.Title)
+			if i, ok := index[t.Id]; ok {
+				merged[i] = updated
+			} else {
+				index[t.Id] = len(merged)
+				merged = append(merged, updated)
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tasks: %w", err)
+		return nil, fmt.Errorf("failed to sync tasks: %w", apiError(err))
 	}
 
 	var tasksList []*Task
-	for _, t := range resp.Items {
-		tasksList = append(tasksList, convertTask(t, taskListID, taskListName))
+	for _, t := range merged {
+		if !removed[t.ID] {
+			tasksList = append(tasksList, t)
+		}
 	}
 	return tasksList, nil
 }
 
+// forEachList runs fn for every list, bounded by the client's worker count.
+// All lists are processed even if some fail; their errors are joined.
+func (c *Client) forEachList(ctx context.Context, lists []*TaskList, fn func(i int, list *TaskList) error) error {
+	errs := make([]error, len(lists))
+	sem := make(chan struct{}, c.workers)
+
+	var wg sync.WaitGroup
+	for i, list := range lists {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, list *TaskList) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(i, list); err != nil {
+				errs[i] = fmt.Errorf("task list '%s': %w", list.Title, err)
+			}
+		}(i, list)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// listTasksFromList fetches the tasks of one list. If filter.MaxPages cut the list
+// short, the tasks fetched are returned with a *TruncatedError.
+func (c *Client) listTasksFromList(ctx context.Context, taskListID, taskListName string, filter TaskFilter) ([]*Task, error) {
+	var tasksList []*Task
+	err := c.streamTasksFromList(ctx, taskListID, taskListName, filter, func(page []*Task) error {
+		tasksList = append(tasksList, page...)
+		return nil
+	})
+	var truncated *TruncatedError
+	if err != nil && !errors.As(err, &truncated) {
+		return nil, err
+	}
+	return tasksList, err
+}
+
+func (c *Client) streamTasksFromList(ctx context.Context, taskListID, taskListName string, filter TaskFilter, fn func([]*Task) error) error {
+	// Tasks completed in other clients are often hidden, so include hidden tasks with completed ones
+	call := c.service.Tasks.List(taskListID).
+		ShowCompleted(filter.showCompleted()).
+		ShowHidden(filter.showCompleted() && !filter.VisibleOnly)
+	if filter.CompletedMin != "" {
+		completedMin, err := formatDateBound(filter.CompletedMin, false)
+		if err != nil {
+			return err
+		}
+		call = call.CompletedMin(completedMin)
+	}
+	if filter.CompletedMax != "" {
+		completedMax, err := formatDateBound(filter.CompletedMax, true)
+		if err != nil {
+			return err
+		}
+		call = call.CompletedMax(completedMax)
+	}
+
+	var fnErr error
+	truncated, err := c.eachTaskPage(ctx, call, filter.MaxPages, func(resp *tasks.Tasks) error {
+		var page []*Task
+		for _, t := range resp.Items {
+			if filter.CompletedOnly && t.Status != StatusCompleted {
+				continue
+			}
+			page = append(page, convertTask(t, taskListID, taskListName))
+		}
+		if err := fn(page); err != nil {
+			fnErr = err
+			return errStopPaging
+		}
+		return nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get tasks: %w", apiError(err))
+	}
+	if truncated {
+		return &TruncatedError{Pages: filter.MaxPages}
+	}
+	return nil
+}
+
+// eachTaskPage runs a task list request page by page with the client's page size,
+// stopping after maxPages pages unless it is 0. It reports whether it stopped there
+// with more pages left.
+func (c *Client) eachTaskPage(ctx context.Context, call *tasks.TasksListCall, maxPages int, fn func(*tasks.Tasks) error) (bool, error) {
+	pages := 0
+	truncated := false
+	err := call.MaxResults(c.paging.MaxResults).Pages(ctx, func(resp *tasks.Tasks) error {
+		if err := fn(resp); err != nil {
+			return err
+		}
+		pages++
+		if maxPages > 0 && pages >= maxPages && resp.NextPageToken != "" {
+			truncated = true
+			return errStopPaging
+		}
+		return nil
+	})
+	if errors.Is(err, errStopPaging) {
+		return truncated, nil
+	}
+	return false, err
+}
+
 // GetTask returns a task by ID from a specific task list
 func (c *Client) GetTask(ctx context.Context, taskListID, taskID string) (*Task, error) {
 	// Try to resolve short ID
@@ -196,9 +563,14 @@ func (c *Client) GetTask(ctx context.Context, taskListID, taskID string) (*Task,
 		return nil, err
 	}
 
+	return c.getTask(ctx, taskListID, fullID)
+}
+
+// getTask fetches a task by its full ID from the API, bypassing the cache
+func (c *Client) getTask(ctx context.Context, taskListID, fullID string) (*Task, error) {
 	t, err := c.service.Tasks.Get(taskListID, fullID).Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get task: %w", err)
+		return nil, fmt.Errorf("failed to get task: %w", apiError(err))
 	}
 	listName, _ := c.GetTaskListName(ctx, taskListID)
 	return convertTask(t, taskListID, listName), nil
@@ -234,57 +606,181 @@ func (c *Client) FindTask(ctx context.Context, taskID string) (*Task, error) {
 
 // CreateTask creates a new task
 func (c *Client) CreateTask(ctx context.Context, taskListID string, task *Task) (*Task, error) {
-	newTask := &tasks.Task{
-		Title: task.Title,
-		Notes: task.Notes,
+	parentID := ""
+	if task.Parent != "" {
+		id, err := c.ResolveTaskID(ctx, taskListID, task.Parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve parent task: %w", err)
+		}
+		parentID = id
+	}
+
+	listName, _ := c.GetTaskListName(ctx, taskListID)
+	created, err := c.insertTask(ctx, taskListID, listName, task, parentID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
-	if task.Due != "" {
-		newTask.Due = FormatDueDate(task.Due)
+
+	// Add to cache
+	c.addTaskToCache(created)
+
+	return created, nil
+}
+
+// insertTask creates a task under parentID after previous, both full IDs or empty,
+// without updating the cache. CreateTask and CreateTasks both insert through it.
+func (c *Client) insertTask(ctx context.Context, taskListID, listName string, task *Task, parentID, previous string) (*Task, error) {
+	newTask := &tasks.Task{
+		Title:  task.Title,
+		Notes:  task.Notes,
+		Due:    FormatDueDate(task.Due),
+		Status: StatusNeedsAction,
 	}
 	if task.Status == StatusCompleted {
 		newTask.Status = StatusCompleted
 	}
 
-	t, err := c.service.Tasks.Insert(taskListID, newTask).Context(ctx).Do()
+	call := c.service.Tasks.Insert(taskListID, newTask)
+	if parentID != "" {
+		call = call.Parent(parentID)
+	}
+	if previous != "" {
+		call = call.Previous(previous)
+	}
+
+	t, err := call.Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create task: %w", err)
+		return nil, apiError(err)
 	}
+	return convertTask(t, taskListID, listName), nil
+}
 
-	listName, _ := c.GetTaskListName(ctx, taskListID)
-	created := convertTask(t, taskListID, listName)
+// TruncatedError is returned along with the tasks of a list when TaskFilter.MaxPages
+// stopped the listing before its last page
+type TruncatedError struct {
+	// Pages is the number of pages fetched
+	Pages int
+}
 
-	// Add to cache
-	c.addTaskToCache(created)
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("showing only the first %d page(s) of tasks; set max_pages to 0 to show all", e.Pages)
+}
 
-	return created, nil
+// ConflictError is returned by PatchTask when the task was changed on the server
+// after the version in TaskChanges.Etag was read
+type ConflictError struct {
+	// Current is the task as it is now on the server
+	Current *Task
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("task '%s' was changed elsewhere at %s", e.Current.Title, e.Current.Updated)
+}
+
+// TaskChanges lists the fields PatchTask changes; nil fields are left as they are
+type TaskChanges struct {
+	Title *string
+	Notes *string
+	// Due is a date (YYYY-MM-DD), or empty to remove the due date
+	Due    *string
+	Status *string
+	// Etag, if set, makes PatchTask fail with a *ConflictError instead of changing a
+	// task that was changed on the server since this version
+	Etag string
+}
+
+// DiffTask returns the changes that turn from into to, comparing title, notes, due
+// date and status
+func DiffTask(from, to *Task) TaskChanges {
+	var changes TaskChanges
+	if to.Title != from.Title {
+		changes.Title = &to.Title
+	}
+	if to.Notes != from.Notes {
+		changes.Notes = &to.Notes
+	}
+	if to.Due != from.Due {
+		changes.Due = &to.Due
+	}
+	if to.Status != from.Status {
+		changes.Status = &to.Status
+	}
+	return changes
+}
+
+// Fields returns the names of the changed fields
+func (ch TaskChanges) Fields() []string {
+	var fields []string
+	if ch.Title != nil {
+		fields = append(fields, "title")
+	}
+	if ch.Due != nil {
+		fields = append(fields, "due")
+	}
+	if ch.Status != nil {
+		fields = append(fields, "status")
+	}
+	if ch.Notes != nil {
+		fields = append(fields, "notes")
+	}
+	return fields
 }
 
-// UpdateTask updates an existing task
-func (c *Client) UpdateTask(ctx context.Context, taskListID string, task *Task) (*Task, error) {
-	fullID, err := c.ResolveTaskID(ctx, taskListID, task.ID)
+// IsEmpty reports whether no field is changed
+func (ch TaskChanges) IsEmpty() bool {
+	return len(ch.Fields()) == 0
+}
+
+// PatchTask changes only the given fields of a task with one PATCH request, after
+// resolving a short ID. With changes.Etag set, the request is sent with If-Match, and
+// the 412 returned when the task has changed since is reported as a *ConflictError.
+func (c *Client) PatchTask(ctx context.Context, taskListID, taskID string, changes TaskChanges) (*Task, error) {
+	fullID, err := c.ResolveTaskID(ctx, taskListID, taskID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get existing task first
-	existing, err := c.service.Tasks.Get(taskListID, fullID).Context(ctx).Do()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get task: %w", err)
+	patch := &tasks.Task{}
+	if changes.Title != nil {
+		patch.Title = *changes.Title
+		patch.ForceSendFields = append(patch.ForceSendFields, "Title")
+	}
+	if changes.Notes != nil {
+		patch.Notes = *changes.Notes
+		patch.ForceSendFields = append(patch.ForceSendFields, "Notes")
+	}
+	if changes.Due != nil {
+		if *changes.Due == "" {
+			patch.NullFields = append(patch.NullFields, "Due")
+		} else {
+			patch.Due = FormatDueDate(*changes.Due)
+		}
+	}
+	if changes.Status != nil {
+		patch.Status = *changes.Status
+		if patch.Status != StatusCompleted {
+			// Reopened tasks lose their completion time and are shown again
+			patch.NullFields = append(patch.NullFields, "Completed")
+			patch.ForceSendFields = append(patch.ForceSendFields, "Hidden")
+		}
 	}
 
-	// Update fields
-	existing.Title = task.Title
-	existing.Notes = task.Notes
-	existing.Due = FormatDueDate(task.Due)
-	if task.Status == StatusCompleted {
-		existing.Status = StatusCompleted
-	} else {
-		existing.Status = StatusNeedsAction
+	call := c.service.Tasks.Patch(taskListID, fullID, patch)
+	if changes.Etag != "" {
+		call.Header().Set("If-Match", changes.Etag)
 	}
 
-	t, err := c.service.Tasks.Update(taskListID, fullID, existing).Context(ctx).Do()
+	t, err := call.Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("failed to update task: %w", err)
+		var gerr *googleapi.Error
+		if errors.As(err, &gerr) && gerr.Code == http.StatusPreconditionFailed {
+			current, getErr := c.getTask(ctx, taskListID, fullID)
+			if getErr != nil {
+				return nil, getErr
+			}
+			return nil, &ConflictError{Current: current}
+		}
+		return nil, fmt.Errorf("failed to update task: %w", apiError(err))
 	}
 
 	listName, _ := c.GetTaskListName(ctx, taskListID)
@@ -300,29 +796,81 @@ func (c *Client) UpdateTask(ctx context.Context, taskListID string, task *Task)
 	return updated, nil
 }
 
-// CompleteTask marks a task as completed
-func (c *Client) CompleteTask(ctx context.Context, taskListID, taskID string) (*Task, error) {
+// MoveOptions specifies where a task is moved to
+type MoveOptions struct {
+	// DestinationListID is the task list to move the task to; empty keeps the current list
+	DestinationListID string
+	// Parent is the new parent task ID; empty makes the task a top-level task
+	Parent string
+	// Previous is the sibling task to place the task after; empty places it first
+	Previous string
+}
+
+// MoveTask moves a task to another position, parent or task list.
+// The task keeps its ID, completion state and subtasks.
+func (c *Client) MoveTask(ctx context.Context, taskListID, taskID string, opts MoveOptions) (*Task, error) {
 	fullID, err := c.ResolveTaskID(ctx, taskListID, taskID)
 	if err != nil {
 		return nil, err
 	}
 
-	existing, err := c.service.Tasks.Get(taskListID, fullID).Context(ctx).Do()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get task: %w", err)
+	destListID := taskListID
+	if opts.DestinationListID != "" {
+		destListID = opts.DestinationListID
+	}
+
+	call := c.service.Tasks.Move(taskListID, fullID)
+	if opts.Parent != "" {
+		parentID, err := c.ResolveTaskID(ctx, destListID, opts.Parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve parent task: %w", err)
+		}
+		call = call.Parent(parentID)
+	}
+	if opts.Previous != "" {
+		previousID, err := c.ResolveTaskID(ctx, destListID, opts.Previous)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve previous task: %w", err)
+		}
+		call = call.Previous(previousID)
 	}
 
-	existing.Status = StatusCompleted
-	t, err := c.service.Tasks.Update(taskListID, fullID, existing).Context(ctx).Do()
+	var callOpts []googleapi.CallOption
+	if destListID != taskListID {
+		callOpts = append(callOpts, googleapi.QueryParameter("destinationTasklist", destListID))
+	}
+
+	t, err := call.Context(ctx).Do(callOpts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to complete task: %w", err)
+		return nil, fmt.Errorf("failed to move task: %w", apiError(err))
 	}
 
-	// Remove from cache (completed tasks are not cached)
-	c.removeTaskFromCache(fullID)
+	listName, _ := c.GetTaskListName(ctx, destListID)
+	moved := convertTask(t, destListID, listName)
+	c.updateTaskInCache(moved)
 
-	listName, _ := c.GetTaskListName(ctx, taskListID)
-	return convertTask(t, taskListID, listName), nil
+	return moved, nil
+}
+
+// CompleteTask marks a task as completed
+func (c *Client) CompleteTask(ctx context.Context, taskListID, taskID string) (*Task, error) {
+	status := StatusCompleted
+	return c.PatchTask(ctx, taskListID, taskID, TaskChanges{Status: &status})
+}
+
+// UncompleteTask marks a completed task as needing action again
+func (c *Client) UncompleteTask(ctx context.Context, taskListID, taskID string) (*Task, error) {
+	status := StatusNeedsAction
+	return c.PatchTask(ctx, taskListID, taskID, TaskChanges{Status: &status})
+}
+
+// ClearCompleted hides all completed tasks in the specified task list. The cache holds
+// only incomplete, visible tasks, so it needs no update.
+func (c *Client) ClearCompleted(ctx context.Context, taskListID string) error {
+	if err := c.service.Tasks.Clear(taskListID).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to clear completed tasks: %w", apiError(err))
+	}
+	return nil
 }
 
 // DeleteTask deletes a task
@@ -333,7 +881,7 @@ func (c *Client) DeleteTask(ctx context.Context, taskListID, taskID string) erro
 	}
 
 	if err := c.service.Tasks.Delete(taskListID, fullID).Context(ctx).Do(); err != nil {
-		return fmt.Errorf("failed to delete task: %w", err)
+		return fmt.Errorf("failed to delete task: %w", apiError(err))
 	}
 
 	// Remove from cache
@@ -362,20 +910,21 @@ func (c *Client) ResolveTaskID(ctx context.Context, taskListID, shortID string)
 	}
 
 	// Search for matching task
-	resp, err := c.service.Tasks.List(taskListID).
+	call := c.service.Tasks.List(taskListID).
 		ShowCompleted(true).
-		ShowHidden(true).
-		Context(ctx).
-		Do()
-	if err != nil {
-		return "", fmt.Errorf("failed to search tasks: %w", err)
-	}
+		ShowHidden(true)
 
 	var matches []string
-	for _, t := range resp.Items {
-		if strings.HasPrefix(t.Id, shortID) {
-			matches = append(matches, t.Id)
+	_, err = c.eachTaskPage(ctx, call, 0, func(resp *tasks.Tasks) error {
+		for _, t := range resp.Items {
+			if strings.HasPrefix(t.Id, shortID) {
+				matches = append(matches, t.Id)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search tasks: %w", apiError(err))
 	}
 
 	if len(matches) == 0 {
@@ -395,13 +944,30 @@ func ShortID(id string) string {
 	return id[:8]
 }
 
-// saveToCache saves task lists and tasks to cache
-func (c *Client) saveToCache(lists []*TaskList, tasks []*Task) {
+// cachedTasks returns the cached incomplete tasks of all lists, or nil if the cache
+// is missing or expired
+func (c *Client) cachedTasks() []*Task {
+	if c.cache == nil {
+		return nil
+	}
+	cached := c.cache.Load()
+	if cached == nil || len(cached.Tasks) == 0 {
+		return nil
+	}
+	var tasks []*Task
+	for _, t := range cached.Tasks {
+		tasks = append(tasks, taskFromCache(t))
+	}
+	return tasks
+}
+
+// saveToCache saves task lists, tasks and per-list sync watermarks to cache
+func (c *Client) saveToCache(lists []*TaskList, tasks []*Task, syncedAt map[string]time.Time) {
 	if c.cache == nil {
 		return
 	}
 
-	data := &cache.CacheData{}
+	data := &cache.CacheData{SyncedAt: syncedAt}
 
 	for _, l := range lists {
 		data.TaskLists = append(data.TaskLists, cache.TaskListCache{
@@ -449,6 +1015,10 @@ func taskFromCache(c cache.TaskCache) *Task {
 		Notes:        c.Notes,
 		Due:          c.Due,
 		Status:       c.Status,
+		Parent:       c.Parent,
+		Position:     c.Position,
+		Etag:         c.Etag,
+		Updated:      c.Updated,
 		TaskListID:   c.TaskListID,
 		TaskListName: c.TaskListName,
 	}
@@ -462,6 +1032,10 @@ func taskToCache(t *Task) cache.TaskCache {
 		Notes:        t.Notes,
 		Due:          t.Due,
 		Status:       t.Status,
+		Parent:       t.Parent,
+		Position:     t.Position,
+		Etag:         t.Etag,
+		Updated:      t.Updated,
 		TaskListID:   t.TaskListID,
 		TaskListName: t.TaskListName,
 	}
@@ -480,6 +1054,10 @@ func convertTask(t *tasks.Task, taskListID, taskListName string) *Task {
 		Due:          ParseDueDate(t.Due),
 		Status:       t.Status,
 		Completed:    completed,
+		Parent:       t.Parent,
+		Position:     t.Position,
+		Etag:         t.Etag,
+		Updated:      t.Updated,
 		TaskListID:   taskListID,
 		TaskListName: taskListName,
 	}
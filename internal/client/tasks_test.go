@@ -0,0 +1,365 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/t3yamoto/gt/internal/cache"
+)
+
+func TestPatchTaskConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		etag     string
+		conflict bool
+		// gets is the number of GET requests: one to resolve the ID and, on a
+		// conflict, one for the current task
+		gets int32
+	}{
+		{"current etag", `"v2"`, false, 1},
+		{"stale etag", `"v1"`, true, 2},
+		{"no etag", "", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gets, patches atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.URL.Path == "/tasks/v1/users/@me/lists/list1":
+					io.WriteString(w, `{"id": "list1", "title": "Inbox"}`)
+				case r.URL.Path != "/tasks/v1/lists/list1/tasks/task1":
+					http.NotFound(w, r)
+				case r.Method == http.MethodGet:
+					gets.Add(1)
+					io.WriteString(w, `{"id": "task1", "title": "Theirs", "status": "needsAction", "etag": "\"v2\""}`)
+				case r.Method == http.MethodPatch:
+					patches.Add(1)
+					ifMatch := r.Header.Get("If-Match")
+					if ifMatch != tt.etag {
+						t.Errorf("If-Match = %q, want %q", ifMatch, tt.etag)
+					}
+					if ifMatch != "" && ifMatch != `"v2"` {
+						w.WriteHeader(http.StatusPreconditionFailed)
+						io.WriteString(w, `{"error": {"code": 412, "message": "Precondition Failed"}}`)
+						return
+					}
+					io.WriteString(w, `{"id": "task1", "title": "Mine", "status": "needsAction", "etag": "\"v3\""}`)
+				}
+			}))
+			defer server.Close()
+
+			c, err := NewClient(context.Background(), WithBaseURL(server.URL), WithoutCache(), WithRetry(testRetryOptions))
+			if err != nil {
+				t.Fatal(err)
+			}
+			title := "Mine"
+			_, err = c.PatchTask(context.Background(), "list1", "task1", TaskChanges{Title: &title, Etag: tt.etag})
+
+			var conflict *ConflictError
+			if errors.As(err, &conflict) != tt.conflict {
+				t.Fatalf("err = %v, want conflict: %t", err, tt.conflict)
+			}
+			if tt.conflict && (conflict.Current.Title != "Theirs" || conflict.Current.Etag != `"v2"`) {
+				t.Errorf("conflict with %+v", conflict.Current)
+			}
+			if !tt.conflict && err != nil {
+				t.Fatal(err)
+			}
+			if got := patches.Load(); got != 1 {
+				t.Errorf("patches sent = %d, want 1", got)
+			}
+			if got := gets.Load(); got != tt.gets {
+				t.Errorf("gets sent = %d, want %d", got, tt.gets)
+			}
+		})
+	}
+}
+
+// pagedServer serves list1 with one task per page, in the given number of pages
+func pagedServer(t *testing.T, pages int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/tasks/v1/users/@me/lists":
+			io.WriteString(w, `{"items": [{"id": "list1", "title": "Inbox"}]}`)
+		case "/tasks/v1/users/@me/lists/list1":
+			io.WriteString(w, `{"id": "list1", "title": "Inbox"}`)
+		case "/tasks/v1/lists/list1/tasks":
+			page := 1
+			if token := r.URL.Query().Get("pageToken"); token != "" {
+				page, _ = strconv.Atoi(token)
+			}
+			next := ""
+			if page < pages {
+				next = strconv.Itoa(page + 1)
+			}
+			fmt.Fprintf(w, `{"items": [{"id": "task%d", "title": "Task %d", "status": "needsAction"}], "nextPageToken": %q}`, page, page, next)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestListTasksMaxPages(t *testing.T) {
+	tests := []struct {
+		pages, maxPages int
+		tasks           int
+		truncated       bool
+	}{
+		{pages: 3, maxPages: 0, tasks: 3},
+		{pages: 3, maxPages: 1, tasks: 1, truncated: true},
+		{pages: 3, maxPages: 2, tasks: 2, truncated: true},
+		{pages: 3, maxPages: 3, tasks: 3},
+		{pages: 2, maxPages: 5, tasks: 2},
+	}
+
+	for _, tt := range tests {
+		server := pagedServer(t, tt.pages)
+		c, err := NewClient(context.Background(), WithBaseURL(server.URL), WithoutCache(), WithRetry(testRetryOptions))
+		if err != nil {
+			t.Fatal(err)
+		}
+		filter := TaskFilter{IncludeCompleted: true, MaxPages: tt.maxPages}
+
+		for name, list := range map[string]func() ([]*Task, error){
+			"ListTasksFiltered":    func() ([]*Task, error) { return c.ListTasksFiltered(context.Background(), "list1", filter) },
+			"ListAllTasksFiltered": func() ([]*Task, error) { return c.ListAllTasksFiltered(context.Background(), filter) },
+		} {
+			tasks, err := list()
+			var truncated *TruncatedError
+			if errors.As(err, &truncated) != tt.truncated || (err != nil && !tt.truncated) {
+				t.Errorf("%s with %d of %d pages: err = %v, want truncated: %t", name, tt.maxPages, tt.pages, err, tt.truncated)
+			}
+			if tt.truncated && truncated.Pages != tt.maxPages {
+				t.Errorf("%s: truncated after %d pages, want %d", name, truncated.Pages, tt.maxPages)
+			}
+			if len(tasks) != tt.tasks {
+				t.Errorf("%s with %d of %d pages: %d task(s), want %d", name, tt.maxPages, tt.pages, len(tasks), tt.tasks)
+			}
+		}
+	}
+}
+
+func TestListTasksFromLists(t *testing.T) {
+	lists := []*TaskList{{ID: "a", Title: "A"}, {ID: "b", Title: "B"}, {ID: "c", Title: "C"}, {ID: "d", Title: "D"}, {ID: "e", Title: "E"}}
+
+	tests := []struct {
+		name    string
+		workers int
+		// delays holds how long each list takes to answer; later lists answer first
+		delays map[string]time.Duration
+		failed []string
+		cancel bool
+	}{
+		{name: "sequential", workers: 1},
+		{name: "concurrent, out of order", workers: 3, delays: map[string]time.Duration{"a": 30 * time.Millisecond, "b": 20 * time.Millisecond, "c": 10 * time.Millisecond}},
+		{name: "failed lists", workers: 2, delays: map[string]time.Duration{"a": 10 * time.Millisecond}, failed: []string{"b", "e"}},
+		{name: "all failed", workers: 5, failed: []string{"a", "b", "c", "d", "e"}},
+		{name: "cancelled", workers: 2, cancel: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			failed := map[string]bool{}
+			for _, id := range tt.failed {
+				failed[id] = true
+			}
+
+			var mu sync.Mutex
+			var requests, running, maxRunning int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				id, ok := strings.CutPrefix(r.URL.Path, "/tasks/v1/lists/")
+				id, ok2 := strings.CutSuffix(id, "/tasks")
+				if !ok || !ok2 {
+					http.NotFound(w, r)
+					return
+				}
+
+				mu.Lock()
+				requests++
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+				defer func() {
+					mu.Lock()
+					running--
+					mu.Unlock()
+				}()
+
+				if tt.cancel {
+					// Hold every request until the caller gives up
+					cancel()
+					<-r.Context().Done()
+					return
+				}
+				time.Sleep(tt.delays[id])
+				w.Header().Set("Content-Type", "application/json")
+				if failed[id] {
+					w.WriteHeader(http.StatusNotFound)
+					io.WriteString(w, `{"error": {"code": 404, "message": "Not Found"}}`)
+					return
+				}
+				fmt.Fprintf(w, `{"items": [{"id": "%s1", "title": "%s1", "status": "needsAction"}]}`, id, strings.ToUpper(id))
+			}))
+			defer server.Close()
+
+			c, err := NewClient(context.Background(), WithBaseURL(server.URL), WithoutCache(), WithRetry(testRetryOptions))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.SetWorkers(tt.workers)
+
+			tasks, err := c.listTasksFromLists(ctx, lists, TaskFilter{})
+			server.Close() // wait for the requests still being served
+
+			if maxRunning > tt.workers {
+				t.Errorf("%d lists fetched at once, want at most %d", maxRunning, tt.workers)
+			}
+			if tt.cancel {
+				if !errors.Is(err, context.Canceled) || tasks != nil {
+					t.Errorf("got %d task(s), err = %v, want context.Canceled", len(tasks), err)
+				}
+				// Lists waiting for a worker are not fetched after cancellation
+				if requests > tt.workers {
+					t.Errorf("%d lists fetched after cancellation, want at most %d", requests, tt.workers)
+				}
+				return
+			}
+
+			// Tasks of the lists that succeeded, in list order
+			var want []string
+			for _, list := range lists {
+				if !failed[list.ID] {
+					want = append(want, list.Title+"1")
+				}
+			}
+			var got []string
+			for _, task := range tasks {
+				got = append(got, task.Title)
+			}
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("tasks = %v, want %v", got, want)
+			}
+
+			// One joined error per failed list, in list order
+			var errs []error
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				errs = joined.Unwrap()
+			} else if err != nil {
+				t.Fatalf("err = %v, want errors joined with errors.Join", err)
+			}
+			if len(errs) != len(tt.failed) {
+				t.Fatalf("err = %v, want %d error(s)", err, len(tt.failed))
+			}
+			for i, id := range tt.failed {
+				prefix := fmt.Sprintf("task list '%s': ", strings.ToUpper(id))
+				if !strings.HasPrefix(errs[i].Error(), prefix) {
+					t.Errorf("error %d = %q, want prefix %q", i, errs[i], prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncTasks(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+
+	since := time.Now().Add(-time.Hour).Truncate(time.Second)
+	lists := []*TaskList{{ID: "inbox", Title: "Inbox"}, {ID: "work", Title: "Work"}, {ID: "old", Title: "Old"}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/tasks/v1/lists/inbox/tasks":
+			// Only changes since the watermark, including deleted, hidden and completed tasks
+			if got, want := q.Get("updatedMin"), since.UTC().Format(time.RFC3339); got != want {
+				t.Errorf("updatedMin = %q, want %q", got, want)
+			}
+			for _, param := range []string{"showCompleted", "showDeleted", "showHidden"} {
+				if q.Get(param) != "true" {
+					t.Errorf("%s = %q, want true", param, q.Get(param))
+				}
+			}
+			io.WriteString(w, `{"items": [
+				{"id": "t2", "title": "Changed", "status": "needsAction"},
+				{"id": "t3", "title": "Deleted", "status": "needsAction", "deleted": true},
+				{"id": "t4", "title": "Completed", "status": "completed"},
+				{"id": "t5", "title": "Hidden", "status": "completed", "hidden": true},
+				{"id": "t6", "title": "New", "status": "needsAction"}
+			]}`)
+		case "/tasks/v1/lists/work/tasks", "/tasks/v1/lists/old/tasks":
+			// Without a recent watermark, the list is fetched in full
+			if q.Has("updatedMin") {
+				t.Errorf("%s fetched with updatedMin", r.URL.Path)
+			}
+			io.WriteString(w, `{"items": [{"id": "full", "title": "Full", "status": "needsAction"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(context.Background(), WithBaseURL(server.URL), WithRetry(testRetryOptions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.cache == nil {
+		t.Fatal("no cache")
+	}
+	c.cache.Save(&cache.CacheData{
+		Tasks: []cache.TaskCache{
+			{ID: "t1", Title: "Kept", Status: StatusNeedsAction, TaskListID: "inbox"},
+			{ID: "t2", Title: "Original", Status: StatusNeedsAction, TaskListID: "inbox"},
+			{ID: "t3", Title: "Deleted", Status: StatusNeedsAction, TaskListID: "inbox"},
+			{ID: "t4", Title: "Completed", Status: StatusNeedsAction, TaskListID: "inbox"},
+			{ID: "t5", Title: "Hidden", Status: StatusNeedsAction, TaskListID: "inbox"},
+			{ID: "o1", Title: "Stale", Status: StatusNeedsAction, TaskListID: "old"},
+		},
+		SyncedAt: map[string]time.Time{"inbox": since, "old": time.Now().Add(-maxSyncAge - time.Hour)},
+	})
+
+	before := time.Now().Add(-syncSkew)
+	tasks, syncedAt, err := c.syncTasks(context.Background(), lists)
+	after := time.Now().Add(-syncSkew)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, task := range tasks {
+		got = append(got, task.TaskListName+"/"+task.ID+"/"+task.Title)
+	}
+	want := []string{"Inbox/t1/Kept", "Inbox/t2/Changed", "Inbox/t6/New", "Work/full/Full", "Old/full/Full"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("tasks = %v, want %v", got, want)
+	}
+
+	for _, list := range lists {
+		if w := syncedAt[list.ID]; w.Before(before) || w.After(after) {
+			t.Errorf("watermark of %s = %s, want between %s and %s", list.ID, w, before, after)
+		}
+	}
+}
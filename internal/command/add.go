@@ -1,7 +1,12 @@
 package command
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/t3yamoto/gt/internal/client"
 	"github.com/t3yamoto/gt/internal/editor"
@@ -12,24 +17,101 @@ func AddCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "add",
 		Usage:     "Add a task (opens editor if no argument)",
-		ArgsUsage: "[title]",
+		ArgsUsage: "[title | -]",
+		Description: "The title may contain due:<date> and @<list> to set the due date and task list,\n" +
+			"e.g. gt add \"Pay rent due:1st @Finance\". Flags take precedence over them.\n\n" +
+			"With --from-file, or - as the title to read stdin, one task is added per line.\n" +
+			"Markdown checklists are accepted, and indented lines become subtasks.",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:    "tasklist",
-				Aliases: []string{"l"},
-				Value:   client.DefaultTaskList,
-				Usage:   "Target task list name",
+				Aliases: []string{"l", "list"},
+				Usage:   "Target task list name (default: setting default_list)",
+			},
+			&cli.StringFlag{
+				Name:    "due",
+				Aliases: []string{"d"},
+				Usage:   "Due date (YYYY-MM-DD, today, tomorrow, fri, next mon, +3d, 2w, eom, ...)",
+			},
+			&cli.StringFlag{
+				Name:    "notes",
+				Aliases: []string{"n"},
+				Usage:   "Notes",
+			},
+			&cli.StringFlag{
+				Name:  "notes-file",
+				Usage: "Read notes from a file, or from stdin if -",
+			},
+			&cli.StringFlag{
+				Name:    "parent",
+				Aliases: []string{"p"},
+				Usage:   "Parent task ID (creates a subtask)",
+			},
+			&cli.BoolFlag{
+				Name:  "done",
+				Usage: "Create the task as completed",
+			},
+			&cli.StringFlag{
+				Name:    "from-file",
+				Aliases: []string{"f"},
+				Usage:   "Add one task per line from a file, or from stdin if -",
+			},
+			&cli.BoolFlag{
+				Name:  "literal",
+				Usage: "Use the title as is, without parsing due: and @list",
 			},
 		},
 		Action: func(c *cli.Context) error {
 			ctx := c.Context
-			taskListName := c.String("tasklist")
 
-			taskClient, err := client.NewClient(ctx)
+			if path := c.String("from-file"); path != "" {
+				return addBatch(c, path)
+			}
+			if c.Args().Len() == 1 && c.Args().First() == "-" {
+				return addBatch(c, "-")
+			}
+
+			due, err := client.ParseDate(c.String("due"))
+			if err != nil {
+				return fmt.Errorf("invalid due date: %w", err)
+			}
+
+			notes, err := readNotes(c)
 			if err != nil {
 				return err
 			}
 
+			status := client.StatusNeedsAction
+			if c.Bool("done") {
+				status = client.StatusCompleted
+			}
+
+			taskClient, err := newTaskService(c)
+			if err != nil {
+				return err
+			}
+
+			inline := quickAdd{Title: c.Args().First()}
+			if c.Args().Len() > 0 && !c.Bool("literal") {
+				isList, err := taskListLookup(ctx, taskClient, inline.Title)
+				if err != nil {
+					return err
+				}
+				inline = parseQuickAdd(inline.Title, time.Now(), isList)
+			}
+			if c.Args().Len() > 0 && strings.TrimSpace(inline.Title) == "" {
+				return fmt.Errorf("title is required")
+			}
+
+			taskListName := targetTaskList(c)
+			if c.String("tasklist") == "" && inline.TaskList != "" {
+				taskListName = inline.TaskList
+			}
+
+			if due == "" {
+				due = inline.Due
+			}
+
 			// Resolve task list name to ID
 			taskListID, err := taskClient.ResolveTaskListID(ctx, taskListName)
 			if err != nil {
@@ -42,39 +124,40 @@ func AddCommand() *cli.Command {
 			var newTask *client.Task
 
 			if c.Args().Len() > 0 {
-				// Simple mode: create task with title from argument
-				title := c.Args().First()
+				// Simple mode: create task from the title and flags
 				newTask = &client.Task{
-					Title: title,
+					Title:  inline.Title,
+					Notes:  notes,
+					Due:    due,
+					Status: status,
+					Parent: c.String("parent"),
 				}
 			} else {
 				// Editor mode
-				initialContent := editor.GenerateEmptyMarkdown(displayName)
-				editedContent, err := editor.Open(initialContent)
+				parsed, listID, err := editNewTask(ctx, taskClient, taskListID, editor.GenerateEmptyMarkdown(displayName))
 				if err != nil {
 					return err
 				}
-
-				if editor.IsEmpty(editedContent) {
+				if parsed == nil {
 					fmt.Println("Cancelled.")
 					return nil
 				}
+				taskListID = listID
 
-				parsed, err := editor.ParseMarkdown(editedContent)
-				if err != nil {
-					return err
+				// Flags fill in whatever was left empty in the editor
+				newTask = parsed
+				if newTask.Notes == "" {
+					newTask.Notes = notes
 				}
-
-				// If task list was changed in editor, resolve new task list
-				editorTaskList := parsed.GetTaskListName()
-				if editorTaskList != displayName && editorTaskList != client.DefaultTaskList {
-					taskListID, err = taskClient.ResolveTaskListID(ctx, editorTaskList)
-					if err != nil {
-						return err
-					}
+				if newTask.Due == "" {
+					newTask.Due = due
+				}
+				if newTask.Parent == "" {
+					newTask.Parent = c.String("parent")
+				}
+				if c.Bool("done") {
+					newTask.Status = client.StatusCompleted
 				}
-
-				newTask = parsed.ToTask()
 			}
 
 			// Create task
@@ -88,3 +171,76 @@ func AddCommand() *cli.Command {
 		},
 	}
 }
+
+// editNewTask opens content in the editor and returns the task written there with the
+// ID of its list, or a nil task if the document was emptied
+func editNewTask(ctx context.Context, taskClient client.TaskService, taskListID, content string) (*client.Task, string, error) {
+	var parsed *editor.TaskMarkdown
+	session := &editor.Session{Kind: editor.SessionAdd, TaskListID: taskListID}
+	_, err := editor.OpenValid(content, func(edited string) (err error) {
+		parsed = nil
+		if editor.IsEmpty(edited) {
+			return nil
+		}
+		parsed, err = editor.ParseMarkdown(edited)
+		return err
+	}, session)
+	if err != nil || parsed == nil {
+		return nil, "", err
+	}
+
+	// If task list was changed in editor, resolve new task list
+	displayName, _ := taskClient.GetTaskListName(ctx, taskListID)
+	editorTaskList := parsed.GetTaskListName()
+	if editorTaskList != displayName && editorTaskList != client.DefaultTaskList {
+		taskListID, err = taskClient.ResolveTaskListID(ctx, editorTaskList)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	return parsed.ToTask(), taskListID, nil
+}
+
+// taskListLookup returns a check for task list names, used to tell quick-add @<list>
+// words apart from other @ words. Lists are only fetched when the title has an @.
+func taskListLookup(ctx context.Context, taskClient client.TaskService, title string) (func(string) bool, error) {
+	if !strings.Contains(title, "@") {
+		return nil, nil
+	}
+	lists, err := taskClient.GetTaskLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(name string) bool {
+		for _, list := range lists {
+			if list.Title == name {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// readNotes returns the notes given with --notes or --notes-file
+func readNotes(c *cli.Context) (string, error) {
+	path := c.String("notes-file")
+	if path == "" {
+		return c.String("notes"), nil
+	}
+	if c.String("notes") != "" {
+		return "", fmt.Errorf("--notes and --notes-file cannot be used together")
+	}
+
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read notes: %w", err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
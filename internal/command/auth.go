@@ -0,0 +1,113 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/t3yamoto/gt/internal/auth"
+	"github.com/t3yamoto/gt/internal/cache"
+	"github.com/urfave/cli/v2"
+)
+
+func AuthCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "auth",
+		Usage: "Manage authentication",
+		Subcommands: []*cli.Command{
+			authLoginCommand(),
+			authStatusCommand(),
+			authLogoutCommand(),
+			authRevokeCommand(),
+		},
+	}
+}
+
+func authLoginCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "login",
+		Usage: "Sign in to Google (falls back to --device when no display is available)",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "device",
+				Aliases: []string{"no-browser"},
+				Usage:   "Sign in without a local browser by pasting back the redirect URL",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if err := auth.Login(c.Context, auth.LoginOptions{Manual: c.Bool("device")}); err != nil {
+				return err
+			}
+
+			fmt.Println("Signed in.")
+			return nil
+		},
+	}
+}
+
+func authStatusCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Show the signed-in account and token details",
+		Action: func(c *cli.Context) error {
+			status, err := auth.GetStatus(c.Context)
+			if err != nil {
+				return err
+			}
+
+			email := status.Email
+			if email == "" {
+				email = "unknown (run `gt auth login` again to show it)"
+			}
+			refresh := "no"
+			if status.HasRefreshToken {
+				refresh = "yes"
+			}
+
+			fmt.Printf("Account:        %s\n", email)
+			fmt.Printf("Scopes:         %s\n", strings.Join(status.Scopes, ", "))
+			fmt.Printf("Expires:        %s\n", status.Expiry.Local().Format("2006-01-02 15:04:05"))
+			fmt.Printf("Refresh token:  %s\n", refresh)
+			fmt.Printf("Token store:    %s\n", status.TokenStore)
+			return nil
+		},
+	}
+}
+
+func authLogoutCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "logout",
+		Usage: "Delete the stored token and cache",
+		Action: func(c *cli.Context) error {
+			if err := auth.Logout(); err != nil {
+				return err
+			}
+			invalidateCache()
+
+			fmt.Println("Signed out.")
+			return nil
+		},
+	}
+}
+
+func authRevokeCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "revoke",
+		Usage: "Revoke gt's access at Google and delete the stored token and cache",
+		Action: func(c *cli.Context) error {
+			if err := auth.Revoke(c.Context); err != nil {
+				return err
+			}
+			invalidateCache()
+
+			fmt.Println("Access revoked and signed out.")
+			return nil
+		},
+	}
+}
+
+// invalidateCache removes cached task data so it is not shown to the next account
+func invalidateCache() {
+	if c, err := cache.New(); err == nil {
+		c.Invalidate()
+	}
+}
@@ -0,0 +1,168 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/t3yamoto/gt/internal/client"
+	"github.com/urfave/cli/v2"
+)
+
+// listItemPattern matches a markdown list marker with an optional checkbox:
+// "- ", "* ", "+ ", "1. ", "- [ ] " or "- [x] "
+var listItemPattern = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(?:\[([ xX])\]\s+)?`)
+
+// headingPattern matches a markdown heading of any level, such as "# Trip" or "### Day 1"
+var headingPattern = regexp.MustCompile(`^#+(?:\s|$)`)
+
+// parseTaskLines reads one task per line. Markdown list markers and checkboxes are
+// removed ("- [x]" creates a completed task), and lines indented deeper than the one
+// before them become its subtasks. Blank lines and markdown headings are skipped.
+// Unless literal is set, "due:<date>" words set a task's due date.
+func parseTaskLines(r io.Reader, literal bool) ([]*client.TaskNode, error) {
+	type level struct {
+		indent int
+		node   *client.TaskNode
+	}
+
+	var roots []*client.TaskNode
+	var stack []level
+	now := time.Now()
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		text := strings.TrimLeft(line, " \t")
+		if text == "" || headingPattern.MatchString(text) {
+			continue
+		}
+		indent := indentWidth(line[:len(line)-len(text)])
+
+		task := &client.Task{Status: client.StatusNeedsAction}
+		if m := listItemPattern.FindStringSubmatch(text); m != nil {
+			text = text[len(m[0]):]
+			if strings.EqualFold(m[1], "x") {
+				task.Status = client.StatusCompleted
+			}
+		}
+
+		task.Title = text
+		if !literal {
+			q := parseQuickAdd(text, now, nil)
+			task.Title, task.Due = q.Title, q.Due
+		}
+		if task.Title == "" {
+			return nil, fmt.Errorf("line %d: title is required", lineNo)
+		}
+
+		// Find the closest less indented line; this task is its subtask
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		node := &client.TaskNode{Task: task}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1].node
+			parent.Subtasks = append(parent.Subtasks, node)
+		}
+		stack = append(stack, level{indent: indent, node: node})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	return roots, nil
+}
+
+// indentWidth measures leading whitespace, counting a tab as four spaces
+func indentWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// addBatch creates the tasks read from path ("-" for stdin) in one go
+func addBatch(c *cli.Context, path string) error {
+	ctx := c.Context
+
+	if c.String("notes") != "" || c.String("notes-file") != "" {
+		return fmt.Errorf("--notes and --notes-file cannot be used when adding several tasks")
+	}
+	due, err := client.ParseDate(c.String("due"))
+	if err != nil {
+		return fmt.Errorf("invalid due date: %w", err)
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open task file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	roots, err := parseTaskLines(r, c.Bool("literal"))
+	if err != nil {
+		return err
+	}
+	if len(roots) == 0 {
+		fmt.Println("No tasks to add.")
+		return nil
+	}
+
+	// Flags apply to every task that does not set its own value
+	var apply func(nodes []*client.TaskNode)
+	apply = func(nodes []*client.TaskNode) {
+		for _, n := range nodes {
+			if n.Task.Due == "" {
+				n.Task.Due = due
+			}
+			if c.Bool("done") {
+				n.Task.Status = client.StatusCompleted
+			}
+			apply(n.Subtasks)
+		}
+	}
+	apply(roots)
+	for _, n := range roots {
+		n.Task.Parent = c.String("parent")
+	}
+
+	taskClient, err := newTaskService(c)
+	if err != nil {
+		return err
+	}
+	taskListID, err := taskClient.ResolveTaskListID(ctx, targetTaskList(c))
+	if err != nil {
+		return err
+	}
+
+	created, err := taskClient.CreateTasks(ctx, taskListID, roots)
+	if len(created) > 0 {
+		fmt.Printf("Added %d task(s):\n", len(created))
+		depth := make(map[string]int, len(created))
+		for _, t := range created {
+			d := 0
+			if pd, ok := depth[t.Parent]; ok {
+				d = pd + 1
+			}
+			depth[t.ID] = d
+			fmt.Printf("  %s  %s%s\n", client.ShortID(t.ID), strings.Repeat("  ", d), t.Title)
+		}
+	}
+	return err
+}
@@ -0,0 +1,443 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/t3yamoto/gt/internal/client"
+	"github.com/t3yamoto/gt/internal/editor"
+	"github.com/urfave/cli/v2"
+)
+
+// bulkEntry is one task of an edited bulk document, matched to the task it came from
+type bulkEntry struct {
+	orig     *client.Task // nil for a task to add
+	want     *client.Task // desired state; Parent is a full ID
+	changes  []string     // fields changed on an existing task
+	move     bool         // whether the task must be moved to its place
+	previous *bulkEntry   // sibling to place the task after, nil for first
+	id       string       // full ID, known once the task exists
+}
+
+// bulkPlan is the set of changes made in a bulk-edit document
+type bulkPlan struct {
+	entries []*bulkEntry // in document order
+	deletes []*client.Task
+}
+
+// count returns the number of changes the plan makes, as listed by printBulkPlan
+func (p *bulkPlan) count() int {
+	n := len(p.deletes)
+	for _, e := range p.entries {
+		if e.orig == nil || len(e.changes) > 0 {
+			n++
+		}
+		if e.orig != nil && e.move {
+			n++
+		}
+	}
+	return n
+}
+
+// bulkEdit edits all tasks of a list in one editor session and applies the differences
+func bulkEdit(c *cli.Context) error {
+	taskClient, err := newTaskService(c)
+	if err != nil {
+		return err
+	}
+	taskListID, err := taskClient.ResolveTaskListID(c.Context, targetTaskList(c))
+	if err != nil {
+		return err
+	}
+	return bulkEditList(c, taskClient, taskListID, c.Bool("all"), "")
+}
+
+// bulkEditList opens content, or a document generated from the list's tasks if it is
+// empty, and applies the differences to the list
+func bulkEditList(c *cli.Context, taskClient client.TaskService, taskListID string, includeCompleted bool, content string) error {
+	ctx := c.Context
+
+	listName, _ := taskClient.GetTaskListName(ctx, taskListID)
+	tasks, err := taskClient.ListTasksFiltered(ctx, taskListID, client.TaskFilter{IncludeCompleted: includeCompleted})
+	if err != nil {
+		return err
+	}
+	tasks = treeOrder(tasks)
+
+	initialContent := editor.GenerateBulkMarkdown(tasks, listName)
+	if content == "" {
+		content = initialContent
+	}
+
+	var plan *bulkPlan
+	session := &editor.Session{Kind: editor.SessionBulk, TaskListID: taskListID, Completed: includeCompleted}
+	editedContent, err := editor.OpenValid(content, func(edited string) error {
+		parsed, err := editor.ParseBulkMarkdown(edited)
+		if err != nil {
+			return err
+		}
+		plan, err = planBulkEdit(tasks, parsed)
+		return err
+	}, session)
+	if err != nil {
+		return err
+	}
+	if editedContent == "" {
+		fmt.Println("Cancelled.")
+		return nil
+	}
+	if plan.count() == 0 {
+		fmt.Println("No changes made.")
+		return nil
+	}
+
+	printBulkPlan(plan, listName)
+	if !c.Bool("yes") {
+		ok, err := confirm(fmt.Sprintf("Apply %d change(s)?", plan.count()))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	applied, err := applyBulkPlan(ctx, taskClient, taskListID, plan)
+	if err != nil {
+		if len(applied) > 0 {
+			fmt.Println("Applied before the error:")
+			for _, change := range applied {
+				fmt.Println(change)
+			}
+		}
+		return fmt.Errorf("stopped after %d of %d change(s): %w", len(applied), plan.count(), err)
+	}
+
+	fmt.Printf("Applied %d change(s).\n", len(applied))
+	return nil
+}
+
+// treeOrder sorts tasks by position with subtasks right after their parent.
+// Tasks whose parent is not in the slice are treated as top-level tasks.
+func treeOrder(tasks []*client.Task) []*client.Task {
+	byID := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = true
+	}
+
+	var roots []*client.Task
+	children := make(map[string][]*client.Task)
+	for _, t := range tasks {
+		if t.Parent != "" && byID[t.Parent] {
+			children[t.Parent] = append(children[t.Parent], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	byPosition := func(s []*client.Task) {
+		sort.SliceStable(s, func(i, j int) bool { return s[i].Position < s[j].Position })
+	}
+	byPosition(roots)
+
+	ordered := make([]*client.Task, 0, len(tasks))
+	var add func(t *client.Task)
+	add = func(t *client.Task) {
+		ordered = append(ordered, t)
+		byPosition(children[t.ID])
+		for _, child := range children[t.ID] {
+			add(child)
+		}
+	}
+	for _, t := range roots {
+		add(t)
+	}
+	return ordered
+}
+
+// planBulkEdit compares the edited document with the tasks it was generated from
+func planBulkEdit(tasks []*client.Task, edited []*editor.BulkTask) (*bulkPlan, error) {
+	// findTask matches a (possibly short) ID against the original tasks
+	findTask := func(id string) (*client.Task, error) {
+		var found *client.Task
+		for _, t := range tasks {
+			if t.ID == id {
+				return t, nil
+			}
+			if strings.HasPrefix(t.ID, id) {
+				if found != nil {
+					return nil, fmt.Errorf("task ID '%s' matches multiple tasks, please use a longer ID", id)
+				}
+				found = t
+			}
+		}
+		return found, nil
+	}
+
+	plan := &bulkPlan{}
+	seen := make(map[string]bool)
+	for _, bt := range edited {
+		want := bt.ToTask()
+		e := &bulkEntry{want: want}
+
+		if want.ID != "" {
+			orig, err := findTask(want.ID)
+			if err != nil {
+				return nil, err
+			}
+			e.orig = orig
+			if e.orig == nil {
+				return nil, fmt.Errorf("task '%s' is not in this list", want.ID)
+			}
+			if seen[e.orig.ID] {
+				return nil, fmt.Errorf("task '%s' appears more than once", want.ID)
+			}
+			seen[e.orig.ID] = true
+			e.id = e.orig.ID
+			want.ID = e.orig.ID
+		}
+
+		switch {
+		case want.Parent == "":
+		case e.orig != nil && e.orig.Parent != "" && strings.HasPrefix(e.orig.Parent, want.Parent):
+			// Unchanged, even if the parent was not loaded
+			want.Parent = e.orig.Parent
+		default:
+			parent, err := findTask(want.Parent)
+			if err != nil {
+				return nil, err
+			}
+			if parent == nil {
+				return nil, fmt.Errorf("parent '%s' of '%s' is not in this list", want.Parent, want.Title)
+			}
+			if parent.ID == want.ID {
+				return nil, fmt.Errorf("task '%s' cannot be its own parent", want.Title)
+			}
+			want.Parent = parent.ID
+		}
+
+		if e.orig != nil {
+			e.changes = client.DiffTask(e.orig, want).Fields()
+		}
+		plan.entries = append(plan.entries, e)
+	}
+
+	deleted := make(map[string]bool)
+	for _, t := range tasks {
+		if !seen[t.ID] {
+			plan.deletes = append(plan.deletes, t)
+			deleted[t.ID] = true
+		}
+	}
+	for _, e := range plan.entries {
+		if deleted[e.want.Parent] {
+			return nil, fmt.Errorf("'%s' is a subtask of deleted task '%s'", e.want.Title, client.ShortID(e.want.Parent))
+		}
+	}
+
+	planMoves(tasks, plan, deleted)
+	return plan, nil
+}
+
+// planMoves decides which tasks to move so siblings end up in document order. It
+// replays the moves on a copy of the current order, moving a task only when its
+// parent or preceding sibling differs from the document. New tasks are always
+// placed with a move after they are created.
+func planMoves(tasks []*client.Task, plan *bulkPlan, deleted map[string]bool) {
+	entryOf := make(map[string]*bulkEntry)
+	for _, e := range plan.entries {
+		if e.orig != nil {
+			entryOf[e.orig.ID] = e
+		}
+	}
+
+	// Current sibling order per parent, without the tasks being deleted
+	siblings := make(map[string][]*bulkEntry)
+	parentOf := make(map[*bulkEntry]string)
+	for _, t := range tasks {
+		if deleted[t.ID] {
+			continue
+		}
+		e := entryOf[t.ID]
+		siblings[t.Parent] = append(siblings[t.Parent], e)
+		parentOf[e] = t.Parent
+	}
+
+	last := make(map[string]*bulkEntry)
+	for _, e := range plan.entries {
+		parent := e.want.Parent
+		e.previous = last[parent]
+		last[parent] = e
+
+		if e.orig != nil {
+			current := siblings[parentOf[e]]
+			idx := indexOf(current, e)
+			var before *bulkEntry
+			if idx > 0 {
+				before = current[idx-1]
+			}
+			if parentOf[e] == parent && before == e.previous {
+				continue
+			}
+			siblings[parentOf[e]] = append(current[:idx:idx], current[idx+1:]...)
+		}
+
+		e.move = true
+		at := 0
+		if e.previous != nil {
+			at = indexOf(siblings[parent], e.previous) + 1
+		}
+		group := siblings[parent]
+		siblings[parent] = append(group[:at:at], append([]*bulkEntry{e}, group[at:]...)...)
+		parentOf[e] = parent
+	}
+}
+
+func indexOf(entries []*bulkEntry, e *bulkEntry) int {
+	for i, other := range entries {
+		if other == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// printBulkPlan shows the changes about to be applied
+func printBulkPlan(plan *bulkPlan, listName string) {
+	fmt.Printf("Changes to %s:\n", listName)
+	for _, e := range plan.entries {
+		switch {
+		case e.orig == nil:
+			fmt.Println(bulkChange("add", "", e.want.Title))
+		case len(e.changes) > 0:
+			fmt.Printf("%s (%s)\n", bulkChange("update", e.id, e.want.Title), strings.Join(e.changes, ", "))
+		}
+		if e.orig != nil && e.move {
+			fmt.Println(bulkChange("move", e.id, e.want.Title))
+		}
+	}
+	for _, t := range plan.deletes {
+		fmt.Println(bulkChange("delete", t.ID, t.Title))
+	}
+}
+
+// bulkChange describes one change of a bulk edit on a line
+func bulkChange(action, id, title string) string {
+	return fmt.Sprintf("  %-7s %-8s  %s", action, client.ShortID(id), title)
+}
+
+// applyBulkPlan updates, adds, moves and then deletes tasks, returning the changes
+// applied. Updates go first as they may stop on a conflict that cannot be merged,
+// before anything else was changed, and moves come before deletes so subtasks moved
+// out of a deleted task are not deleted with it. On error, the changes applied so
+// far are returned with it.
+func applyBulkPlan(ctx context.Context, taskClient client.TaskService, taskListID string, plan *bulkPlan) ([]string, error) {
+	var applied []string
+
+	for _, e := range plan.entries {
+		if e.orig == nil || len(e.changes) == 0 {
+			continue
+		}
+		if err := patchBulkEntry(ctx, taskClient, taskListID, e); err != nil {
+			return applied, err
+		}
+		applied = append(applied, bulkChange("update", e.id, e.want.Title))
+	}
+
+	for _, e := range plan.entries {
+		if e.orig != nil {
+			continue
+		}
+		created, err := taskClient.CreateTask(ctx, taskListID, e.want)
+		if err != nil {
+			return applied, err
+		}
+		e.id = created.ID
+		applied = append(applied, bulkChange("add", e.id, e.want.Title))
+	}
+
+	for _, e := range plan.entries {
+		if !e.move {
+			continue
+		}
+		opts := client.MoveOptions{Parent: e.want.Parent}
+		if e.previous != nil {
+			opts.Previous = e.previous.id
+		}
+		if _, err := taskClient.MoveTask(ctx, taskListID, e.id, opts); err != nil {
+			return applied, err
+		}
+		if e.orig != nil {
+			applied = append(applied, bulkChange("move", e.id, e.want.Title))
+		}
+	}
+
+	// Deleting a task deletes its subtasks too
+	deleted := make(map[string]bool, len(plan.deletes))
+	for _, t := range plan.deletes {
+		deleted[t.ID] = true
+	}
+	for _, t := range plan.deletes {
+		if !deleted[t.Parent] {
+			if err := taskClient.DeleteTask(ctx, taskListID, t.ID); err != nil {
+				return applied, err
+			}
+		}
+		applied = append(applied, bulkChange("delete", t.ID, t.Title))
+	}
+
+	return applied, nil
+}
+
+// patchBulkEntry applies the changes made to an existing task, merging them with
+// changes made elsewhere since the document was generated like editTask does. For
+// fields changed on both sides, the user chooses which version to keep.
+func patchBulkEntry(ctx context.Context, taskClient client.TaskService, taskListID string, e *bulkEntry) error {
+	base, mine := e.orig, e.want
+	changes := client.DiffTask(base, mine)
+	changes.Etag = base.Etag
+	for !changes.IsEmpty() {
+		_, err := taskClient.PatchTask(ctx, taskListID, e.id, changes)
+		var conflict *client.ConflictError
+		if !errors.As(err, &conflict) {
+			if err != nil {
+				return err
+			}
+			break
+		}
+
+		theirs := conflict.Current
+		merged, conflicts := mergeTask(base, mine, theirs)
+		choice, err := resolveBulkConflict(e, theirs, conflicts)
+		if err != nil {
+			return err
+		}
+		if choice == keepTheirs {
+			for _, c := range conflicts {
+				c.field.set(merged, c.theirs)
+			}
+		}
+
+		base, mine = theirs, merged
+		changes = client.DiffTask(theirs, merged)
+		changes.Etag = theirs.Etag
+	}
+	e.want = mine
+	return nil
+}
+
+// resolveBulkConflict is resolveConflict for one task of a bulk edit, naming the task
+// and without the choice to edit again
+func resolveBulkConflict(e *bulkEntry, theirs *client.Task, conflicts []fieldConflict) (string, error) {
+	if len(conflicts) == 0 {
+		fmt.Printf("Task '%s' was changed elsewhere at %s; merged those changes with yours.\n", theirs.Title, theirs.Updated)
+		return keepMine, nil
+	}
+	fmt.Println(bulkChange("update", e.id, e.want.Title))
+	fmt.Println(describeConflicts(theirs, conflicts))
+	return choose("Keep [m]ine or [t]heirs?", keepMine, keepTheirs)
+}
@@ -0,0 +1,39 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/t3yamoto/gt/internal/client"
+	"github.com/t3yamoto/gt/internal/editor"
+)
+
+func TestPlanBulkEditIDs(t *testing.T) {
+	tasks := []*client.Task{
+		{ID: "abc10", Title: "One", Status: client.StatusNeedsAction},
+		{ID: "abc20", Title: "Two", Status: client.StatusNeedsAction},
+		{ID: "abc2", Title: "Three", Status: client.StatusNeedsAction},
+	}
+	block := func(id, title, parent string) *editor.BulkTask {
+		return &editor.BulkTask{FrontMatter: editor.BulkFrontMatter{ID: id, Title: title, Parent: parent}}
+	}
+
+	// An exact ID wins over longer IDs it is a prefix of
+	plan, err := planBulkEdit(tasks, []*editor.BulkTask{block("abc1", "One", ""), block("abc2", "Three", ""), block("abc20", "Two", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.entries[1].id != "abc2" || plan.entries[2].id != "abc20" || len(plan.deletes) != 0 {
+		t.Errorf("planned entries %q, %q with %d delete(s)", plan.entries[1].id, plan.entries[2].id, len(plan.deletes))
+	}
+
+	for _, edited := range [][]*editor.BulkTask{
+		{block("abc", "One", "")},
+		{block("abc10", "One", "ab")},
+	} {
+		_, err := planBulkEdit(tasks, edited)
+		if err == nil || !strings.Contains(err.Error(), "matches multiple tasks") {
+			t.Errorf("planBulkEdit with an ambiguous ID: err = %v", err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/t3yamoto/gt/internal/client"
+	"github.com/t3yamoto/gt/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+func ClearCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "clear",
+		Usage: "Hide completed tasks in a task list",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "tasklist",
+				Aliases: []string{"l"},
+				Usage:   "Target task list name (default: setting default_list)",
+			},
+			&cli.BoolFlag{
+				Name:  "all-lists",
+				Usage: "Clear completed tasks in all task lists",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "Only show which tasks would be hidden",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Do not ask for confirmation",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx := c.Context
+
+			taskClient, err := newTaskService(c)
+			if err != nil {
+				return err
+			}
+
+			var lists []*client.TaskList
+			if c.Bool("all-lists") {
+				lists, err = taskClient.GetTaskLists(ctx)
+				if err != nil {
+					return err
+				}
+			} else {
+				taskListID, err := taskClient.ResolveTaskListID(ctx, targetTaskList(c))
+				if err != nil {
+					return err
+				}
+				displayName, _ := taskClient.GetTaskListName(ctx, taskListID)
+				lists = []*client.TaskList{{ID: taskListID, Title: displayName}}
+			}
+
+			// Preview the completed tasks that are still visible
+			filter := client.TaskFilter{CompletedOnly: true, VisibleOnly: true}
+			var toClear []*client.TaskList
+			var tasks []*client.Task
+			for _, list := range lists {
+				listTasks, err := taskClient.ListTasksFiltered(ctx, list.ID, filter)
+				if err != nil {
+					return err
+				}
+				if len(listTasks) > 0 {
+					toClear = append(toClear, list)
+					tasks = append(tasks, listTasks...)
+				}
+			}
+
+			if len(tasks) == 0 {
+				fmt.Println("No completed tasks to clear.")
+				return nil
+			}
+
+			output.PrintTasksTable(os.Stdout, tasks)
+			if c.Bool("dry-run") {
+				return nil
+			}
+
+			if !c.Bool("yes") {
+				ok, err := confirm(fmt.Sprintf("Hide %d completed task(s)?", len(tasks)))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					fmt.Println("Cancelled.")
+					return nil
+				}
+			}
+
+			for _, list := range toClear {
+				if err := taskClient.ClearCompleted(ctx, list.ID); err != nil {
+					return err
+				}
+			}
+
+			fmt.Printf("Cleared %d completed task(s).\n", len(tasks))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,556 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/t3yamoto/gt/internal/client"
+	"github.com/t3yamoto/gt/internal/editor"
+	"github.com/t3yamoto/gt/internal/profile"
+	"github.com/urfave/cli/v2"
+)
+
+// editorEnv holds the replacements made by the test binary when it runs as $EDITOR
+const editorEnv = "GT_TEST_EDITOR"
+
+func TestMain(m *testing.M) {
+	if replacements := os.Getenv(editorEnv); replacements != "" {
+		os.Exit(fakeEditor(replacements, os.Args[len(os.Args)-1]))
+	}
+	os.Exit(m.Run())
+}
+
+// fakeEditor applies old/new replacement pairs (JSON) to the file at path
+func fakeEditor(replacements, path string) int {
+	var pairs []string
+	if err := json.Unmarshal([]byte(replacements), &pairs); err != nil {
+		return 1
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 1
+	}
+	content := string(b)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		content = strings.Replace(content, pairs[i], pairs[i+1], 1)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// setEditor makes the editor replace each old string with the following new string
+func setEditor(t *testing.T, oldNew ...string) {
+	t.Helper()
+	b, err := json.Marshal(oldNew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDITOR", os.Args[0])
+	t.Setenv(editorEnv, string(b))
+}
+
+// isolate points the home, config and cache directories at a temporary directory
+func isolate(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CONFIG_HOME", home+"/.config")
+	t.Setenv("XDG_CACHE_HOME", home+"/.cache")
+	t.Setenv(editorEnv, "")
+}
+
+// runGT runs gt with args against svc, feeding stdin, and returns what it printed
+func runGT(t *testing.T, svc client.TaskService, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	if svc != nil {
+		SetServiceFactory(func(c *cli.Context, p profile.Profile) (client.TaskService, error) {
+			return svc, nil
+		})
+		t.Cleanup(func() { SetServiceFactory(defaultServiceFactory) })
+	}
+
+	dir := t.TempDir()
+	in, err := os.Create(dir + "/stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	if _, err := in.WriteString(stdin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.Create(dir + "/stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	oldIn, oldOut := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = in, out
+	defer func() { os.Stdin, os.Stdout = oldIn, oldOut }()
+
+	app := &cli.App{
+		Name: "gt",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "api-url"},
+		},
+		Commands: []*cli.Command{
+			ListCommand(),
+			AddCommand(),
+			DoneCommand(),
+			UndoneCommand(),
+			EditCommand(),
+			DeleteCommand(),
+			MoveCommand(),
+		},
+		ExitErrHandler: func(*cli.Context, error) {},
+	}
+	runErr := app.Run(append([]string{"gt"}, args...))
+
+	printed, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(printed), runErr
+}
+
+// mustRun runs gt and fails the test if the command fails
+func mustRun(t *testing.T, svc client.TaskService, args ...string) string {
+	t.Helper()
+	out, err := runGT(t, svc, "", args...)
+	if err != nil {
+		t.Fatalf("gt %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+// titles returns the titles of all tasks of the default list in stored order,
+// with subtasks prefixed by their parent's title
+func titles(t *testing.T, svc client.TaskService) []string {
+	t.Helper()
+	tasks, err := svc.ListTasksFiltered(context.Background(), client.DefaultTaskList, client.TaskFilter{IncludeCompleted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]string)
+	for _, task := range tasks {
+		byID[task.ID] = task.Title
+	}
+	var result []string
+	for _, task := range tasks {
+		if task.Parent != "" {
+			result = append(result, byID[task.Parent]+"/"+task.Title)
+		} else {
+			result = append(result, task.Title)
+		}
+	}
+	return result
+}
+
+// findTitle returns the task with the given title in the default list
+func findTitle(t *testing.T, svc client.TaskService, title string) *client.Task {
+	t.Helper()
+	tasks, err := svc.ListTasksFiltered(context.Background(), client.DefaultTaskList, client.TaskFilter{IncludeCompleted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range tasks {
+		if task.Title == title {
+			return task
+		}
+	}
+	t.Fatalf("task '%s' not found", title)
+	return nil
+}
+
+func assertTitles(t *testing.T, svc client.TaskService, want ...string) {
+	t.Helper()
+	got := titles(t, svc)
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("tasks = %q, want %q", got, want)
+	}
+}
+
+func TestAddDoneDelete(t *testing.T) {
+	isolate(t)
+	svc := client.NewMemoryService()
+
+	out := mustRun(t, svc, "add", "--due", "2030-01-05", "--notes", "2 litres", "Buy milk")
+	if !strings.Contains(out, "Task added: Buy milk") {
+		t.Errorf("add printed %q", out)
+	}
+	task := findTitle(t, svc, "Buy milk")
+	if task.Due != "2030-01-05" || task.Notes != "2 litres" || task.Status != client.StatusNeedsAction {
+		t.Errorf("added task = %+v", task)
+	}
+
+	mustRun(t, svc, "done", client.ShortID(task.ID))
+	if got := findTitle(t, svc, "Buy milk"); got.Status != client.StatusCompleted {
+		t.Errorf("status after done = %s", got.Status)
+	}
+	out = mustRun(t, svc, "list", "--json")
+	if strings.Contains(out, "Buy milk") {
+		t.Errorf("list shows a completed task: %s", out)
+	}
+
+	mustRun(t, svc, "undone", client.ShortID(task.ID))
+	if got := findTitle(t, svc, "Buy milk"); got.Status != client.StatusNeedsAction || got.Completed != "" {
+		t.Errorf("task after undone = %+v", got)
+	}
+
+	mustRun(t, svc, "delete", client.ShortID(task.ID))
+	assertTitles(t, svc)
+}
+
+func TestAddQuickAddAndSubtasks(t *testing.T) {
+	isolate(t)
+	svc := client.NewMemoryService()
+	ctx := context.Background()
+	if _, err := svc.CreateTaskList(ctx, "Errands"); err != nil {
+		t.Fatal(err)
+	}
+
+	mustRun(t, svc, "add", "Post letter @Errands")
+	listID, _ := svc.ResolveTaskListID(ctx, "Errands")
+	tasks, _ := svc.ListTasks(ctx, listID)
+	if len(tasks) != 1 || tasks[0].Title != "Post letter" {
+		t.Errorf("tasks in Errands = %+v", tasks)
+	}
+
+	mustRun(t, svc, "add", "Trip")
+	trip := findTitle(t, svc, "Trip")
+	mustRun(t, svc, "add", "--parent", client.ShortID(trip.ID), "Book hotel")
+	assertTitles(t, svc, "Trip", "Trip/Book hotel")
+
+	// An @ word that is not a list name stays in the title
+	mustRun(t, svc, "add", "Call @Alice due:someday")
+	findTitle(t, svc, "Call @Alice due:someday")
+
+	if _, err := runGT(t, svc, "", "add", "--parent", "nope", "Orphan"); err == nil {
+		t.Error("add with an unknown parent succeeded")
+	}
+}
+
+func TestAddBatch(t *testing.T) {
+	isolate(t)
+	svc := client.NewMemoryService()
+
+	input := "# Trip\n" +
+		"- [ ] Pack\n" +
+		"  ### Clothes\n" +
+		"  - [ ] Socks\n" +
+		"  - [x] Hat\n" +
+		"####\n" +
+		"- #hashtag idea\n" +
+		"\n" +
+		"Book hotel\n"
+	if _, err := runGT(t, svc, input, "add", "-"); err != nil {
+		t.Fatal(err)
+	}
+	// Headings of any level are skipped, but a title may start with #
+	assertTitles(t, svc, "Pack", "Pack/Socks", "Pack/Hat", "#hashtag idea", "Book hotel")
+}
+
+func TestEdit(t *testing.T) {
+	isolate(t)
+	svc := client.NewMemoryService()
+
+	mustRun(t, svc, "add", "--notes", "old notes", "Write report")
+	task := findTitle(t, svc, "Write report")
+
+	setEditor(t, "title: Write report", "title: Write final report", "old notes", "new notes")
+	out := mustRun(t, svc, "edit", client.ShortID(task.ID))
+	if !strings.Contains(out, "Task updated: Write final report") {
+		t.Errorf("edit printed %q", out)
+	}
+	got := findTitle(t, svc, "Write final report")
+	if got.Notes != "new notes" || got.ID != task.ID {
+		t.Errorf("edited task = %+v", got)
+	}
+
+	// Saving the document unchanged changes nothing
+	setEditor(t)
+	out = mustRun(t, svc, "edit", client.ShortID(task.ID))
+	if !strings.Contains(out, "No changes made.") {
+		t.Errorf("unchanged edit printed %q", out)
+	}
+}
+
+func TestEditRecover(t *testing.T) {
+	isolate(t)
+	svc := client.NewMemoryService()
+	mustRun(t, svc, "add", "Write report")
+	task := findTitle(t, svc, "Write report")
+
+	// The first save breaks the front matter and the second, unchanged, gives up
+	setEditor(t, "title: Write report", "title: [Write report")
+	if out, err := runGT(t, svc, "", "edit", client.ShortID(task.ID)); err == nil {
+		t.Fatalf("edit with invalid front matter succeeded\n%s", out)
+	}
+	session, err := editor.LoadSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Kind != editor.SessionEdit || session.TaskID != task.ID || !strings.Contains(session.Content, "title: [Write report") || session.Error == "" {
+		t.Fatalf("saved session = %+v", session)
+	}
+
+	// --recover reopens the rejected content with the error at the top
+	reopened := editor.WithErrorComment(session.Content, errors.New(session.Error))
+	if !strings.HasPrefix(reopened, "# gt: Could not save your changes:\n# gt:   "+strings.Split(session.Error, "\n")[0]+"\n") {
+		t.Fatalf("reopened content = %q", reopened)
+	}
+	setEditor(t, reopened, strings.Replace(session.Content, "[Write report", "Write final report", 1))
+	out := mustRun(t, svc, "edit", "--recover")
+	if !strings.Contains(out, "Task updated: Write final report") {
+		t.Errorf("recover printed %q", out)
+	}
+	if got := findTitle(t, svc, "Write final report"); got.ID != task.ID {
+		t.Errorf("recovered task = %+v", got)
+	}
+
+	// The session is gone once it has been recovered
+	if _, err := editor.LoadSession(); !errors.Is(err, editor.ErrNoSession) {
+		t.Errorf("LoadSession() after recovery = %v, want ErrNoSession", err)
+	}
+	if _, err := runGT(t, svc, "", "edit", "--recover"); !errors.Is(err, editor.ErrNoSession) {
+		t.Errorf("second recover = %v, want ErrNoSession", err)
+	}
+}
+
+func TestEditConflict(t *testing.T) {
+	isolate(t)
+	svc := client.NewMemoryService()
+	mustRun(t, svc, "add", "--notes", "draft", "Write report")
+	task := findTitle(t, svc, "Write report")
+
+	// A field changed only elsewhere is merged without asking
+	notes := "reviewed"
+	hook := &hookService{TaskService: svc, beforePatch: changeElsewhere(t, svc, client.TaskChanges{Notes: &notes})}
+	setEditor(t, "title: Write report", "title: Write final report")
+	out, err := runGT(t, hook, "", "edit", client.ShortID(task.ID))
+	if err != nil {
+		t.Fatalf("edit: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "merged those changes with yours") {
+		t.Errorf("edit printed %q", out)
+	}
+	if got := findTitle(t, svc, "Write final report"); got.Notes != "reviewed" {
+		t.Errorf("merged task = %+v", got)
+	}
+
+	// A field changed on both sides is asked about
+	for _, tt := range []struct{ answer, theirs, want string }{
+		{"t\n", "Report (theirs)", "Report (theirs)"},
+		{"m\n", "Report (elsewhere)", "Report (mine)"},
+	} {
+		current := findTitle(t, svc, titles(t, svc)[0])
+		hook := &hookService{TaskService: svc, beforePatch: changeElsewhere(t, svc, client.TaskChanges{Title: &tt.theirs})}
+		setEditor(t, "title: "+current.Title, "title: Report (mine)", current.Notes, current.Notes+"!")
+		out, err := runGT(t, hook, tt.answer, "edit", client.ShortID(task.ID))
+		if err != nil {
+			t.Fatalf("edit answering %q: %v\n%s", tt.answer, err, out)
+		}
+		if !strings.Contains(out, `mine:   "Report (mine)"`) || !strings.Contains(out, `theirs: "`+tt.theirs+`"`) {
+			t.Errorf("edit printed %q", out)
+		}
+		// Notes were changed in the editor only, so they are kept either way
+		if got := findTitle(t, svc, tt.want); got.Notes != current.Notes+"!" {
+			t.Errorf("task after answering %q = %+v", tt.answer, got)
+		}
+	}
+}
+
+func TestMove(t *testing.T) {
+	isolate(t)
+	svc := client.NewMemoryService()
+
+	for _, title := range []string{"C", "B", "A"} {
+		mustRun(t, svc, "add", title)
+	}
+	// Tasks are added first, so the list is now A, B, C
+	a, b, c := findTitle(t, svc, "A"), findTitle(t, svc, "B"), findTitle(t, svc, "C")
+	mustRun(t, svc, "add", "--parent", client.ShortID(a.ID), "A2")
+	mustRun(t, svc, "add", "--parent", client.ShortID(a.ID), "A1")
+	a1, a2 := findTitle(t, svc, "A1"), findTitle(t, svc, "A2")
+	assertTitles(t, svc, "A", "A/A1", "A/A2", "B", "C")
+
+	// A task placed after a sibling goes after the sibling's subtasks
+	mustRun(t, svc, "move", "--after", client.ShortID(a.ID), client.ShortID(c.ID))
+	assertTitles(t, svc, "A", "A/A1", "A/A2", "C", "B")
+
+	// A subtask moved among its siblings stays under its parent
+	mustRun(t, svc, "move", "--after", client.ShortID(a2.ID), client.ShortID(a1.ID))
+	assertTitles(t, svc, "A", "A/A2", "A/A1", "C", "B")
+
+	mustRun(t, svc, "move", "--top", "--after", client.ShortID(b.ID), client.ShortID(a2.ID))
+	assertTitles(t, svc, "A", "A/A1", "C", "B", "A2")
+
+	mustRun(t, svc, "move", "--parent", client.ShortID(b.ID), client.ShortID(a2.ID))
+	assertTitles(t, svc, "A", "A/A1", "C", "B", "B/A2")
+
+	// A failed move leaves the list as it was
+	if _, err := runGT(t, svc, "", "move", "--after", "nope", client.ShortID(c.ID)); err == nil {
+		t.Error("move after an unknown task succeeded")
+	}
+	assertTitles(t, svc, "A", "A/A1", "C", "B", "B/A2")
+}
+
+func TestListAgainstAPIURL(t *testing.T) {
+	isolate(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/tasks/v1/users/@me/lists":
+			io.WriteString(w, `{"items": [{"id": "list1", "title": "Inbox"}]}`)
+		case "/tasks/v1/lists/list1/tasks":
+			if r.URL.Query().Get("pageToken") == "" {
+				io.WriteString(w, `{"items": [{"id": "task1", "title": "First page", "status": "needsAction"}], "nextPageToken": "p2"}`)
+				return
+			}
+			io.WriteString(w, `{"items": [{"id": "task2", "title": "Second page", "status": "needsAction"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	out := mustRun(t, nil, "--api-url", server.URL, "list", "--json")
+	for _, title := range []string{"First page", "Second page"} {
+		if !strings.Contains(out, title) {
+			t.Errorf("list output misses %q:\n%s", title, out)
+		}
+	}
+}
+
+// hookService wraps a TaskService to simulate changes made elsewhere and failures
+type hookService struct {
+	client.TaskService
+	beforePatch func(taskID string) // run once, before the next PatchTask
+	createErr   error               // returned by CreateTask if set
+}
+
+func (h *hookService) PatchTask(ctx context.Context, taskListID, taskID string, changes client.TaskChanges) (*client.Task, error) {
+	if before := h.beforePatch; before != nil {
+		h.beforePatch = nil
+		before(taskID)
+	}
+	return h.TaskService.PatchTask(ctx, taskListID, taskID, changes)
+}
+
+func (h *hookService) CreateTask(ctx context.Context, taskListID string, task *client.Task) (*client.Task, error) {
+	if h.createErr != nil {
+		return nil, h.createErr
+	}
+	return h.TaskService.CreateTask(ctx, taskListID, task)
+}
+
+// changeElsewhere returns a hook that patches a task as another client would
+func changeElsewhere(t *testing.T, svc client.TaskService, changes client.TaskChanges) func(string) {
+	return func(taskID string) {
+		if _, err := svc.PatchTask(context.Background(), client.DefaultTaskList, taskID, changes); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBulkEdit(t *testing.T) {
+	isolate(t)
+	svc := client.NewMemoryService()
+	for _, title := range []string{"Delta", "Gamma", "Beta", "Alpha"} {
+		mustRun(t, svc, "add", title)
+	}
+	assertTitles(t, svc, "Alpha", "Beta", "Gamma", "Delta")
+
+	block := func(title, extra string) string {
+		id := client.ShortID(findTitle(t, svc, title).ID)
+		return "---\nid: " + id + "\ntitle: " + title + "\n" + extra + "completed: false\n---\n"
+	}
+	alpha, beta, gamma, delta := block("Alpha", ""), block("Beta", ""), block("Gamma", ""), block("Delta", "")
+	alphaID, deltaID := findTitle(t, svc, "Alpha").ID, findTitle(t, svc, "Delta").ID
+	betaID := client.ShortID(findTitle(t, svc, "Beta").ID)
+
+	// Swap Alpha and Delta, delete Beta, move Gamma under Delta and add Epsilon before Alpha
+	setEditor(t,
+		alpha, "<alpha>",
+		beta, "",
+		gamma, block("Gamma", "parent: "+client.ShortID(deltaID)+"\n"),
+		delta, "---\ntitle: Epsilon\ncompleted: false\n---\n"+alpha,
+		"<alpha>", delta)
+	out := mustRun(t, svc, "edit", "--bulk", "--yes")
+	for _, line := range []string{"move    " + client.ShortID(deltaID), "add               Epsilon", "delete  " + betaID, "Applied 4 change(s)."} {
+		if !strings.Contains(out, line) {
+			t.Errorf("bulk edit summary has no %q in %q", line, out)
+		}
+	}
+
+	assertTitles(t, svc, "Delta", "Delta/Gamma", "Epsilon", "Alpha")
+	if got := findTitle(t, svc, "Gamma"); got.Parent != deltaID {
+		t.Errorf("Gamma's parent = %q, want %q", got.Parent, deltaID)
+	}
+	if got := findTitle(t, svc, "Alpha"); got.ID != alphaID {
+		t.Errorf("Alpha was recreated as %s, want it kept as %s", got.ID, alphaID)
+	}
+}
+
+func TestBulkEditReportsPartialResult(t *testing.T) {
+	isolate(t)
+	svc := client.NewMemoryService()
+	mustRun(t, svc, "add", "Alpha")
+	mustRun(t, svc, "add", "Beta")
+
+	// Alpha's notes change elsewhere before the update, and adding Gamma fails
+	notes := "changed elsewhere"
+	hook := &hookService{
+		TaskService: svc,
+		beforePatch: changeElsewhere(t, svc, client.TaskChanges{Notes: &notes}),
+		createErr:   errors.New("quota exceeded"),
+	}
+	setEditor(t, "title: Alpha", "title: Alpha 2",
+		"completed: false\n---\n", "completed: false\n---\n\n---\ntitle: Gamma\ncompleted: false\n---\n")
+	out, err := runGT(t, hook, "", "edit", "--bulk", "--yes")
+	if err == nil || !strings.Contains(err.Error(), "stopped after 1 of 2 change(s): quota exceeded") {
+		t.Fatalf("bulk edit error = %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "merged those changes with yours") || !strings.Contains(out, "Applied before the error:\n  update") {
+		t.Errorf("bulk edit printed %q", out)
+	}
+
+	// The update was applied and merged; the add and the move after it were not
+	if got := findTitle(t, svc, "Alpha 2"); got.Notes != notes {
+		t.Errorf("updated task = %+v", got)
+	}
+	assertTitles(t, svc, "Beta", "Alpha 2")
+}
+
+func TestBulkEditConflict(t *testing.T) {
+	isolate(t)
+	svc := client.NewMemoryService()
+	mustRun(t, svc, "add", "Alpha")
+
+	title := "Alpha elsewhere"
+	hook := &hookService{TaskService: svc, beforePatch: changeElsewhere(t, svc, client.TaskChanges{Title: &title})}
+	setEditor(t, "title: Alpha", "title: Alpha mine")
+	out, err := runGT(t, hook, "t\n", "edit", "--bulk", "--yes")
+	if err != nil {
+		t.Fatalf("bulk edit: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, `mine:   "Alpha mine"`) || !strings.Contains(out, "Keep [m]ine or [t]heirs?") {
+		t.Errorf("bulk edit printed %q", out)
+	}
+	assertTitles(t, svc, "Alpha elsewhere")
+}
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/t3yamoto/gt/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+func ConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Show and change settings",
+		Subcommands: []*cli.Command{
+			configListCommand(),
+			configGetCommand(),
+			configSetCommand(),
+			configUnsetCommand(),
+		},
+		Action: configListCommand().Action,
+	}
+}
+
+func configListCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "List all settings with their effective values and where they come from",
+		Action: func(c *cli.Context) error {
+			fmt.Printf("# %s\n", config.File())
+			for _, s := range config.Settings() {
+				v, err := config.Get(s.Key)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%s=%s  (%s)\n", s.Key, v.Value, v.Source)
+			}
+			return nil
+		},
+	}
+}
+
+func configGetCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "get",
+		Usage:     "Print the effective value of a setting",
+		ArgsUsage: "<key>",
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return fmt.Errorf("usage: gt config get <key>")
+			}
+
+			v, err := config.Get(c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(v.Value)
+			return nil
+		},
+	}
+}
+
+func configSetCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "set",
+		Usage:     "Save a setting to the config file",
+		ArgsUsage: "<key> <value>",
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 2 || c.Args().Get(1) == "" {
+				return fmt.Errorf("usage: gt config set <key> <value>")
+			}
+
+			key, value := c.Args().Get(0), c.Args().Get(1)
+			if err := config.Set(key, value); err != nil {
+				return err
+			}
+
+			fmt.Printf("Set %s=%s in %s\n", key, value, config.File())
+			return nil
+		},
+	}
+}
+
+func configUnsetCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "unset",
+		Usage:     "Remove a setting from the config file, restoring its default",
+		ArgsUsage: "<key>",
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return fmt.Errorf("usage: gt config unset <key>")
+			}
+
+			if err := config.Set(c.Args().First(), ""); err != nil {
+				return err
+			}
+
+			fmt.Printf("Unset %s in %s\n", c.Args().First(), config.File())
+			return nil
+		},
+	}
+}
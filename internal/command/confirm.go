@@ -0,0 +1,42 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// confirm asks a yes/no question on stdin and reports whether the answer was yes
+func confirm(prompt string) (bool, error) {
+	fmt.Printf("%s [y/N]: ", prompt)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// choose asks a question on stdin until one of the options is answered, by its first
+// letter or in full, and returns that option
+func choose(prompt string, options ...string) (string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("%s: ", prompt)
+
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		for _, o := range options {
+			if answer != "" && (answer == o || answer == o[:1]) {
+				return o, nil
+			}
+		}
+	}
+}
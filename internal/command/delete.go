@@ -3,7 +3,6 @@ package command
 import (
 	"fmt"
 
-	"github.com/t3yamoto/gt/internal/client"
 	"github.com/urfave/cli/v2"
 )
 
@@ -22,7 +21,7 @@ func DeleteCommand() *cli.Command {
 		Action: func(c *cli.Context) error {
 			ctx := c.Context
 
-			taskClient, err := client.NewClient(ctx)
+			taskClient, err := newTaskService(c)
 			if err != nil {
 				return err
 			}
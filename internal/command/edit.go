@@ -1,7 +1,10 @@
 package command
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/t3yamoto/gt/internal/client"
 	"github.com/t3yamoto/gt/internal/editor"
@@ -13,17 +16,50 @@ func EditCommand() *cli.Command {
 		Name:      "edit",
 		Usage:     "Edit a task (interactive selection if no argument)",
 		ArgsUsage: "[task-id]",
+		Description: "With --bulk, all tasks of a list are edited in one document. Reorder, change,\n" +
+			"remove or add task blocks, then confirm the summary of changes to apply them.\n\n" +
+			"If an edit cannot be parsed, the editor opens again with the error at the top.\n" +
+			"Saving without changes gives up and keeps a copy, which --recover opens again.",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:    "tasklist",
 				Aliases: []string{"l"},
-				Usage:   "Target task list name (default: all lists)",
+				Usage:   "Target task list name (default: all lists, or setting default_list with --bulk)",
+			},
+			&cli.BoolFlag{
+				Name:    "bulk",
+				Aliases: []string{"b"},
+				Usage:   "Edit all tasks of a list in one document",
+			},
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "With --bulk, include completed tasks",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "With --bulk, apply changes without confirmation",
+			},
+			&cli.BoolFlag{
+				Name:  "recover",
+				Usage: "Resume the last add or edit whose changes could not be saved",
 			},
 		},
 		Action: func(c *cli.Context) error {
 			ctx := c.Context
 
-			taskClient, err := client.NewClient(ctx)
+			if c.Bool("recover") {
+				return recoverSession(c)
+			}
+			if c.Bool("bulk") {
+				if c.Args().Present() {
+					return fmt.Errorf("--bulk edits a whole list and takes no task ID")
+				}
+				return bulkEdit(c)
+			}
+
+			taskClient, err := newTaskService(c)
 			if err != nil {
 				return err
 			}
@@ -33,56 +69,158 @@ func EditCommand() *cli.Command {
 				return err
 			}
 
-			// Open in editor
-			initialContent := editor.GenerateMarkdown(task, task.TaskListName)
-			editedContent, err := editor.Open(initialContent)
+			return editTask(ctx, taskClient, task, taskListID, editor.GenerateMarkdown(task, task.TaskListName))
+		},
+	}
+}
+
+// editTask opens content in the editor and applies it to task
+func editTask(ctx context.Context, taskClient client.TaskService, task *client.Task, taskListID, content string) error {
+	var parsed *editor.TaskMarkdown
+	session := &editor.Session{Kind: editor.SessionEdit, TaskListID: taskListID, TaskID: task.ID}
+	editedContent, err := editor.OpenValid(content, func(edited string) (err error) {
+		parsed, err = editor.ParseMarkdown(edited)
+		return err
+	}, session)
+	if err != nil {
+		return err
+	}
+	if editedContent == "" {
+		fmt.Println("Cancelled.")
+		return nil
+	}
+
+	// Check if content was changed
+	if editedContent == editor.GenerateMarkdown(task, task.TaskListName) {
+		fmt.Println("No changes made.")
+		return nil
+	}
+
+	// Update task
+	updatedTask := parsed.ToTask()
+	updatedTask.ID = task.ID
+
+	// Send only the changed fields, merging with changes made elsewhere meanwhile
+	changes := client.DiffTask(task, updatedTask)
+	changes.Etag = task.Etag
+	for !changes.IsEmpty() {
+		_, err := taskClient.PatchTask(ctx, taskListID, task.ID, changes)
+		var conflict *client.ConflictError
+		if !errors.As(err, &conflict) {
 			if err != nil {
 				return err
 			}
+			break
+		}
 
-			// Check if content was changed
-			if initialContent == editedContent {
-				fmt.Println("No changes made.")
-				return nil
+		theirs := conflict.Current
+		merged, conflicts := mergeTask(task, updatedTask, theirs)
+		choice, err := resolveConflict(theirs, conflicts)
+		if err != nil {
+			return err
+		}
+		switch choice {
+		case keepTheirs:
+			for _, c := range conflicts {
+				c.field.set(merged, c.theirs)
 			}
+		case editAgain:
+			content := editor.GenerateMarkdown(merged, parsed.FrontMatter.TaskList)
+			content = editor.WithComment(content, describeConflicts(theirs, conflicts)+
+				"\nBelow are your changes merged with theirs. Save to apply them.")
+			return editTask(ctx, taskClient, theirs, taskListID, content)
+		}
 
-			// Parse edited content
-			parsed, err := editor.ParseMarkdown(editedContent)
-			if err != nil {
-				return err
-			}
+		task, updatedTask = theirs, merged
+		changes = client.DiffTask(theirs, merged)
+		changes.Etag = theirs.Etag
+	}
+	reparent := parentChanged(updatedTask.Parent, task.Parent)
 
-			// Update task
-			updatedTask := parsed.ToTask()
-			updatedTask.ID = task.ID
+	// Move the task if its list or parent was changed
+	move := reparent
+	moveOpts := client.MoveOptions{Parent: task.Parent}
+	if reparent {
+		moveOpts.Parent = updatedTask.Parent
+	}
+	editorTaskList := parsed.GetTaskListName()
+	if editorTaskList != client.DefaultTaskList && editorTaskList != task.TaskListName {
+		newTaskListID, err := taskClient.ResolveTaskListID(ctx, editorTaskList)
+		if err != nil {
+			return err
+		}
+		moveOpts.DestinationListID = newTaskListID
+		// The old parent does not exist in the new list
+		if !reparent {
+			moveOpts.Parent = ""
+		}
+		move = true
+	}
+	if move {
+		if _, err := taskClient.MoveTask(ctx, taskListID, task.ID, moveOpts); err != nil {
+			return err
+		}
+	}
 
-			// Check if task list was changed
-			editorTaskList := parsed.GetTaskListName()
-			if editorTaskList != client.DefaultTaskList && editorTaskList != task.TaskListName {
-				newTaskListID, err := taskClient.ResolveTaskListID(ctx, editorTaskList)
-				if err != nil {
-					return err
-				}
-				// Need to delete from old list and create in new list
-				if err := taskClient.DeleteTask(ctx, taskListID, task.ID); err != nil {
-					return fmt.Errorf("failed to move task: %w", err)
-				}
-				created, err := taskClient.CreateTask(ctx, newTaskListID, updatedTask)
-				if err != nil {
-					return fmt.Errorf("failed to move task: %w", err)
-				}
-				fmt.Printf("Task updated: %s (new ID: %s)\n", created.Title, client.ShortID(created.ID))
-				return nil
-			}
+	fmt.Printf("Task updated: %s\n", updatedTask.Title)
+	return nil
+}
 
-			// Update in same list
-			updated, err := taskClient.UpdateTask(ctx, taskListID, updatedTask)
-			if err != nil {
-				return err
-			}
+// recoverSession reopens the content of the last editor session that was given up,
+// and finishes the add or edit it belongs to
+func recoverSession(c *cli.Context) error {
+	ctx := c.Context
 
-			fmt.Printf("Task updated: %s\n", updated.Title)
-			return nil
-		},
+	session, err := editor.LoadSession()
+	if err != nil {
+		return err
+	}
+	content := editor.WithErrorComment(session.Content, errors.New(session.Error))
+
+	taskClient, err := newTaskService(c)
+	if err != nil {
+		return err
+	}
+
+	switch session.Kind {
+	case editor.SessionAdd:
+		newTask, taskListID, err := editNewTask(ctx, taskClient, session.TaskListID, content)
+		if err != nil {
+			return err
+		}
+		if newTask == nil {
+			fmt.Println("Cancelled.")
+			break
+		}
+		created, err := taskClient.CreateTask(ctx, taskListID, newTask)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Task added: %s (ID: %s)\n", created.Title, client.ShortID(created.ID))
+	case editor.SessionEdit:
+		task, err := taskClient.GetTask(ctx, session.TaskListID, session.TaskID)
+		if err != nil {
+			return err
+		}
+		if err := editTask(ctx, taskClient, task, session.TaskListID, content); err != nil {
+			return err
+		}
+	case editor.SessionBulk:
+		if err := bulkEditList(c, taskClient, session.TaskListID, session.Completed, content); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown editor session '%s'", session.Kind)
+	}
+
+	return editor.DeleteSession()
+}
+
+// parentChanged reports whether the (possibly short) parent ID from the editor
+// refers to a different parent than the task's current one
+func parentChanged(edited, current string) bool {
+	if edited == "" {
+		return current != ""
 	}
+	return !strings.HasPrefix(current, edited)
 }
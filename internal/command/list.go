@@ -1,10 +1,15 @@
 package command
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/t3yamoto/gt/internal/client"
+	"github.com/t3yamoto/gt/internal/config"
 	"github.com/t3yamoto/gt/internal/output"
+	"github.com/t3yamoto/gt/internal/profile"
 	"github.com/urfave/cli/v2"
 )
 
@@ -22,30 +27,82 @@ func ListCommand() *cli.Command {
 				Name:  "json",
 				Usage: "Output in JSON format",
 			},
+			&cli.BoolFlag{
+				Name:  "completed",
+				Usage: "Show only completed tasks",
+			},
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "Show both incomplete and completed tasks",
+			},
+			&cli.StringFlag{
+				Name:  "completed-min",
+				Usage: "Show tasks completed on or after this date (YYYY-MM-DD, yesterday, -1w, ...)",
+			},
+			&cli.StringFlag{
+				Name:  "completed-max",
+				Usage: "Show tasks completed on or before this date (YYYY-MM-DD, today, ...)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "profiles",
+				Usage: "List tasks from several profiles in one table (comma-separated)",
+			},
+			&cli.BoolFlag{
+				Name:  "flat",
+				Usage: "Do not indent subtasks under their parents",
+			},
 		},
 		Action: func(c *cli.Context) error {
-			ctx := c.Context
-
-			taskClient, err := client.NewClient(ctx)
+			completedMin, err := client.ParseDate(c.String("completed-min"))
+			if err != nil {
+				return fmt.Errorf("invalid --completed-min: %w", err)
+			}
+			completedMax, err := client.ParseDate(c.String("completed-max"))
 			if err != nil {
-				return err
+				return fmt.Errorf("invalid --completed-max: %w", err)
+			}
+
+			filter := client.TaskFilter{
+				IncludeCompleted: c.Bool("all"),
+				CompletedOnly:    c.Bool("completed"),
+				CompletedMin:     completedMin,
+				CompletedMax:     completedMax,
+				MaxPages:         config.MaxPages(),
+			}
+			// Date filters only make sense for completed tasks
+			if !filter.IncludeCompleted && (filter.CompletedMin != "" || filter.CompletedMax != "") {
+				filter.CompletedOnly = true
 			}
 
 			var tasks []*client.Task
 
-			if c.String("tasklist") != "" {
-				// Specific task list
-				taskListID, err := taskClient.ResolveTaskListID(ctx, c.String("tasklist"))
-				if err != nil {
-					return err
+			if names := c.StringSlice("profiles"); len(names) > 0 {
+				// Several profiles in one table
+				for _, name := range names {
+					p, err := profile.Get(name)
+					if err != nil {
+						return err
+					}
+					taskClient, err := newTaskServiceForProfile(c, p)
+					if err != nil {
+						return fmt.Errorf("profile '%s': %w", name, err)
+					}
+					profileTasks, err := listTasks(c, taskClient, filter)
+					if err != nil {
+						return fmt.Errorf("profile '%s': %w", name, err)
+					}
+					for _, t := range profileTasks {
+						t.Profile = p.Name
+					}
+					tasks = append(tasks, profileTasks...)
 				}
-				tasks, err = taskClient.ListTasks(ctx, taskListID)
+			} else {
+				taskClient, err := newTaskService(c)
 				if err != nil {
 					return err
 				}
-			} else {
-				// All task lists
-				tasks, err = taskClient.ListAllTasks(ctx)
+				tasks, err = listTasks(c, taskClient, filter)
 				if err != nil {
 					return err
 				}
@@ -55,8 +112,45 @@ func ListCommand() *cli.Command {
 			if c.Bool("json") {
 				return output.PrintTasksJSON(os.Stdout, tasks)
 			}
-			output.PrintTasksTable(os.Stdout, tasks)
+			if c.Bool("flat") {
+				output.PrintTasksTable(os.Stdout, tasks)
+				return nil
+			}
+			output.PrintTasksTree(os.Stdout, tasks)
 			return nil
 		},
 	}
 }
+
+// listTasks lists the tasks matching filter from the --tasklist list, or from all lists
+func listTasks(c *cli.Context, taskClient client.TaskService, filter client.TaskFilter) ([]*client.Task, error) {
+	ctx := c.Context
+
+	if c.String("tasklist") != "" {
+		// Specific task list
+		taskListID, err := taskClient.ResolveTaskListID(ctx, c.String("tasklist"))
+		if err != nil {
+			return nil, err
+		}
+		tasks, err := taskClient.ListTasksFiltered(ctx, taskListID, filter)
+		var truncated *client.TruncatedError
+		if errors.As(err, &truncated) {
+			fmt.Fprintf(os.Stderr, "Warning: task list '%s': %v\n", c.String("tasklist"), err)
+			return tasks, nil
+		}
+		return tasks, err
+	}
+
+	// All task lists
+	tasks, err := taskClient.ListAllTasksFiltered(ctx, filter)
+	if err != nil {
+		if len(tasks) == 0 {
+			return nil, err
+		}
+		// Show what could be loaded and report the failed or truncated lists
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", line)
+		}
+	}
+	return tasks, nil
+}
@@ -0,0 +1,208 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/t3yamoto/gt/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+func ListsCommand() *cli.Command {
+	ls := listsLsCommand()
+	return &cli.Command{
+		Name:  "lists",
+		Usage: "Manage task lists",
+		Flags: ls.Flags,
+		Subcommands: []*cli.Command{
+			ls,
+			listsAddCommand(),
+			listsRenameCommand(),
+			listsRmCommand(),
+			listsShowCommand(),
+		},
+		Action: ls.Action,
+	}
+}
+
+func listsLsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ls",
+		Usage: "List task lists",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Output in JSON format",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx := c.Context
+
+			taskClient, err := newTaskService(c)
+			if err != nil {
+				return err
+			}
+
+			lists, err := taskClient.GetTaskLists(ctx)
+			if err != nil {
+				return err
+			}
+
+			if c.Bool("json") {
+				return output.PrintTaskListsJSON(os.Stdout, lists)
+			}
+			output.PrintTaskListsTable(os.Stdout, lists)
+			return nil
+		},
+	}
+}
+
+func listsAddCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Create a task list",
+		ArgsUsage: "<name>",
+		Action: func(c *cli.Context) error {
+			ctx := c.Context
+
+			if c.Args().Len() != 1 {
+				return fmt.Errorf("usage: gt lists add <name>")
+			}
+
+			taskClient, err := newTaskService(c)
+			if err != nil {
+				return err
+			}
+
+			created, err := taskClient.CreateTaskList(ctx, c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Task list created: %s\n", created.Title)
+			return nil
+		},
+	}
+}
+
+func listsRenameCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rename",
+		Usage:     "Rename a task list",
+		ArgsUsage: "<name> <new-name>",
+		Action: func(c *cli.Context) error {
+			ctx := c.Context
+
+			if c.Args().Len() != 2 {
+				return fmt.Errorf("usage: gt lists rename <name> <new-name>")
+			}
+
+			taskClient, err := newTaskService(c)
+			if err != nil {
+				return err
+			}
+
+			taskListID, err := taskClient.ResolveTaskListID(ctx, c.Args().Get(0))
+			if err != nil {
+				return err
+			}
+
+			renamed, err := taskClient.RenameTaskList(ctx, taskListID, c.Args().Get(1))
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Task list renamed: %s -> %s\n", c.Args().Get(0), renamed.Title)
+			return nil
+		},
+	}
+}
+
+func listsRmCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rm",
+		Usage:     "Delete a task list and all of its tasks",
+		ArgsUsage: "<name>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Do not ask for confirmation",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx := c.Context
+
+			if c.Args().Len() != 1 {
+				return fmt.Errorf("usage: gt lists rm <name>")
+			}
+			name := c.Args().First()
+
+			taskClient, err := newTaskService(c)
+			if err != nil {
+				return err
+			}
+
+			taskListID, err := taskClient.ResolveTaskListID(ctx, name)
+			if err != nil {
+				return err
+			}
+
+			if !c.Bool("yes") {
+				ok, err := confirm(fmt.Sprintf("Delete task list '%s' and all of its tasks?", name))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					fmt.Println("Cancelled.")
+					return nil
+				}
+			}
+
+			if err := taskClient.DeleteTaskList(ctx, taskListID); err != nil {
+				return err
+			}
+
+			fmt.Printf("Task list deleted: %s\n", name)
+			return nil
+		},
+	}
+}
+
+func listsShowCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "show",
+		Usage:     "Show a task list with its task counts",
+		ArgsUsage: "[name]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Output in JSON format",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx := c.Context
+
+			taskClient, err := newTaskService(c)
+			if err != nil {
+				return err
+			}
+
+			taskListID, err := taskClient.ResolveTaskListID(ctx, c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			stats, err := taskClient.GetTaskListStats(ctx, taskListID)
+			if err != nil {
+				return err
+			}
+
+			if c.Bool("json") {
+				return output.PrintTaskListStatsJSON(os.Stdout, stats)
+			}
+			output.PrintTaskListStats(os.Stdout, stats)
+			return nil
+		},
+	}
+}
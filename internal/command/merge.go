@@ -0,0 +1,85 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/t3yamoto/gt/internal/client"
+)
+
+// mergeField is a task field that gt edit merges with changes made elsewhere
+type mergeField struct {
+	name string
+	get  func(t *client.Task) string
+	set  func(t *client.Task, v string)
+}
+
+var mergeFields = []mergeField{
+	{"title", func(t *client.Task) string { return t.Title }, func(t *client.Task, v string) { t.Title = v }},
+	{"due", func(t *client.Task) string { return t.Due }, func(t *client.Task, v string) { t.Due = v }},
+	{"status", func(t *client.Task) string { return t.Status }, func(t *client.Task, v string) { t.Status = v }},
+	{"notes", func(t *client.Task) string { return t.Notes }, func(t *client.Task, v string) { t.Notes = v }},
+}
+
+// fieldConflict is a field changed to different values in the editor and on the server
+type fieldConflict struct {
+	field  mergeField
+	mine   string
+	theirs string
+}
+
+// mergeTask merges the edited task (mine) with the server's current task (theirs),
+// both changed from base. Fields changed on one side only take that side's value;
+// fields changed differently on both sides keep mine and are returned as conflicts.
+func mergeTask(base, mine, theirs *client.Task) (*client.Task, []fieldConflict) {
+	merged := *mine
+
+	var conflicts []fieldConflict
+	for _, f := range mergeFields {
+		b, m, t := f.get(base), f.get(mine), f.get(theirs)
+		switch {
+		case m == b:
+			f.set(&merged, t)
+		case t != b && t != m:
+			conflicts = append(conflicts, fieldConflict{field: f, mine: m, theirs: t})
+		}
+	}
+	return &merged, conflicts
+}
+
+// Answers to a conflict between the editor and the server
+const (
+	keepMine   = "mine"
+	keepTheirs = "theirs"
+	editAgain  = "re-edit"
+)
+
+// resolveConflict tells the user that the task changed elsewhere during the edit and,
+// if some fields were changed on both sides, asks which version to keep
+func resolveConflict(theirs *client.Task, conflicts []fieldConflict) (string, error) {
+	if len(conflicts) == 0 {
+		fmt.Printf("Task was changed elsewhere at %s; merged those changes with yours.\n", theirs.Updated)
+		return keepMine, nil
+	}
+
+	fmt.Println(describeConflicts(theirs, conflicts))
+	return choose("Keep [m]ine, [t]heirs or [r]e-edit?", keepMine, keepTheirs, editAgain)
+}
+
+// describeConflicts lists the fields changed both in the editor and elsewhere
+func describeConflicts(theirs *client.Task, conflicts []fieldConflict) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Task was changed elsewhere at %s while you were editing it:", theirs.Updated)
+	for _, c := range conflicts {
+		fmt.Fprintf(&sb, "\n  %s\n    mine:   %s\n    theirs: %s", c.field.name, conflictValue(c.mine), conflictValue(c.theirs))
+	}
+	return sb.String()
+}
+
+// conflictValue shows a field value on one line
+func conflictValue(v string) string {
+	if v == "" {
+		return "(empty)"
+	}
+	return fmt.Sprintf("%q", v)
+}
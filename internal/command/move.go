@@ -0,0 +1,81 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/t3yamoto/gt/internal/client"
+	"github.com/urfave/cli/v2"
+)
+
+func MoveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "move",
+		Usage:     "Move a task to another list, parent or position (interactive selection if no argument)",
+		ArgsUsage: "[task-id]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "tasklist",
+				Aliases: []string{"l"},
+				Usage:   "Source task list name (default: all lists)",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "Destination task list name (default: current list)",
+			},
+			&cli.StringFlag{
+				Name:  "after",
+				Usage: "Place the task after this sibling task ID",
+			},
+			&cli.StringFlag{
+				Name:    "parent",
+				Aliases: []string{"p"},
+				Usage:   "Make the task a subtask of this task ID",
+			},
+			&cli.BoolFlag{
+				Name:  "top",
+				Usage: "Make a subtask a top-level task",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx := c.Context
+
+			taskClient, err := newTaskService(c)
+			if err != nil {
+				return err
+			}
+
+			task, taskListID, err := ResolveTask(ctx, taskClient, c.Args().First(), c.String("tasklist"))
+			if err != nil {
+				return err
+			}
+
+			if c.Bool("top") && c.String("parent") != "" {
+				return fmt.Errorf("--top and --parent cannot be used together")
+			}
+
+			opts := client.MoveOptions{
+				Parent:   c.String("parent"),
+				Previous: c.String("after"),
+			}
+			// Subtasks stay under their parent unless told otherwise; moving to
+			// another list makes them top-level tasks there
+			if opts.Parent == "" && !c.Bool("top") && c.String("to") == "" {
+				opts.Parent = task.Parent
+			}
+			if c.String("to") != "" {
+				opts.DestinationListID, err = taskClient.ResolveTaskListID(ctx, c.String("to"))
+				if err != nil {
+					return err
+				}
+			}
+
+			moved, err := taskClient.MoveTask(ctx, taskListID, task.ID, opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Task moved: %s (list: %s)\n", moved.Title, moved.TaskListName)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/t3yamoto/gt/internal/profile"
+	"github.com/urfave/cli/v2"
+)
+
+func ProfileCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "profile",
+		Usage: "Manage account profiles",
+		Subcommands: []*cli.Command{
+			profileLsCommand(),
+			profileAddCommand(),
+			profileUseCommand(),
+		},
+		Action: profileLsCommand().Action,
+	}
+}
+
+func profileLsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ls",
+		Usage: "List profiles (* marks the active one)",
+		Action: func(c *cli.Context) error {
+			names, err := profile.List()
+			if err != nil {
+				return err
+			}
+
+			active := profile.Current().Name
+			for _, name := range names {
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+				fmt.Printf("%s %s\n", marker, name)
+			}
+			return nil
+		},
+	}
+}
+
+func profileAddCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Create a profile",
+		ArgsUsage: "<name>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "credentials",
+				Usage: "OAuth client credentials file for this profile (default: the shared credentials.json)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return fmt.Errorf("usage: gt profile add <name>")
+			}
+
+			p, err := profile.Add(c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			if path := c.String("credentials"); path != "" {
+				b, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("failed to read credentials: %w", err)
+				}
+				dir, err := p.ConfigDir()
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(filepath.Join(dir, "credentials.json"), b, 0600); err != nil {
+					return fmt.Errorf("failed to save credentials: %w", err)
+				}
+			}
+
+			fmt.Printf("Profile created: %s\n", p.Name)
+			fmt.Printf("Sign in with: gt --profile %s auth login\n", p.Name)
+			return nil
+		},
+	}
+}
+
+func profileUseCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "use",
+		Usage:     "Set the profile used when --profile and GT_PROFILE are not given",
+		ArgsUsage: "<name>",
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return fmt.Errorf("usage: gt profile use <name>")
+			}
+
+			if err := profile.Use(c.Args().First()); err != nil {
+				return err
+			}
+
+			fmt.Printf("Using profile: %s\n", c.Args().First())
+			return nil
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/t3yamoto/gt/internal/client"
+)
+
+// quickAdd holds the fields parsed from a quick-add title
+type quickAdd struct {
+	Title    string
+	Due      string
+	TaskList string
+}
+
+// wordPattern matches a word of a quick-add title
+var wordPattern = regexp.MustCompile(`\S+`)
+
+// parseQuickAdd extracts inline fields from a title such as "Pay rent due:1st @Finance".
+// A word "due:<date>" sets the due date (as YYYY-MM-DD) and a word "@<list>" the task
+// list; use underscores for spaces ("due:next_fri", "@Side_Projects"). Only words that
+// parse are taken out, with one adjoining run of whitespace; the rest of the title is kept
+// as typed, so a date that does not parse or an "@" word that isList rejects stays in it.
+// Relative dates count from now. With isList nil, "@" words are always kept.
+func parseQuickAdd(input string, now time.Time, isList func(name string) bool) quickAdd {
+	var q quickAdd
+	var b strings.Builder
+	kept := false    // whether a word has been kept yet
+	dropGap := false // whether to drop the whitespace before the next kept word
+	pos := 0
+	for _, w := range wordPattern.FindAllStringIndex(input, -1) {
+		gap, word := input[pos:w[0]], input[w[0]:w[1]]
+		pos = w[1]
+		if q.parseWord(word, now, isList) {
+			// Drop the word with the whitespace before it, or the whitespace after
+			// it while nothing has been kept, so the title does not start with a gap
+			if !kept {
+				if !dropGap {
+					b.WriteString(gap)
+				}
+				dropGap = true
+			}
+			continue
+		}
+		if !dropGap {
+			b.WriteString(gap)
+		}
+		b.WriteString(word)
+		kept, dropGap = true, false
+	}
+	if !dropGap {
+		b.WriteString(input[pos:])
+	}
+	q.Title = b.String()
+	return q
+}
+
+// parseWord sets the field a quick-add word stands for, reporting whether it was one
+func (q *quickAdd) parseWord(word string, now time.Time, isList func(name string) bool) bool {
+	if len(word) > len("due:") && strings.EqualFold(word[:len("due:")], "due:") {
+		due, err := client.ParseDateAt(strings.ReplaceAll(word[len("due:"):], "_", " "), now)
+		if err != nil || due == "" {
+			return false
+		}
+		q.Due = due
+		return true
+	}
+
+	name, ok := strings.CutPrefix(word, "@")
+	if !ok || name == "" || isList == nil {
+		return false
+	}
+	for _, candidate := range []string{strings.ReplaceAll(name, "_", " "), name} {
+		if isList(candidate) {
+			q.TaskList = candidate
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package command
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	// The last day of a month, so "tomorrow" also checks the rollover
+	now := time.Date(2024, 1, 31, 23, 30, 0, 0, time.Local)
+	tomorrow := "2024-02-01"
+	isList := func(name string) bool { return name == "Finance" || name == "Side Projects" }
+
+	tests := []struct {
+		input string
+		want  quickAdd
+	}{
+		{"Pay rent due:tomorrow @Finance", quickAdd{Title: "Pay rent", Due: tomorrow, TaskList: "Finance"}},
+		{"Plan trip @Side_Projects", quickAdd{Title: "Plan trip", TaskList: "Side Projects"}},
+		{"DUE:tomorrow  Pay   rent", quickAdd{Title: "Pay   rent", Due: tomorrow}},
+		{"Pay due:tomorrow rent", quickAdd{Title: "Pay rent", Due: tomorrow}},
+		{"due:tomorrow @Finance Pay\trent ", quickAdd{Title: "Pay\trent ", Due: tomorrow, TaskList: "Finance"}},
+		{"  Indented title", quickAdd{Title: "  Indented title"}},
+		{"Email @bob about due:someday", quickAdd{Title: "Email @bob about due:someday"}},
+		{"Reply to @ and due:", quickAdd{Title: "Reply to @ and due:"}},
+		{"due:tomorrow", quickAdd{Due: tomorrow}},
+	}
+	for _, tt := range tests {
+		if got := parseQuickAdd(tt.input, now, isList); got != tt.want {
+			t.Errorf("parseQuickAdd(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+
+	// Without a list lookup, @ words are part of the title
+	if got := parseQuickAdd("Pay rent @Finance", now, nil); got.Title != "Pay rent @Finance" || got.TaskList != "" {
+		t.Errorf("parseQuickAdd without lists = %+v", got)
+	}
+}
@@ -10,14 +10,20 @@ import (
 // ResolveTask resolves a task either by ID or interactive selection
 // If taskID is provided, it searches for the task (optionally within taskListName)
 // If taskID is empty, it presents an interactive selector
-func ResolveTask(ctx context.Context, c *client.Client, taskID, taskListName string) (*client.Task, string, error) {
+func ResolveTask(ctx context.Context, c client.TaskService, taskID, taskListName string) (*client.Task, string, error) {
+	return ResolveTaskWithFilter(ctx, c, taskID, taskListName, client.TaskFilter{})
+}
+
+// ResolveTaskWithFilter works like ResolveTask, but the interactive selector
+// only offers tasks matching filter
+func ResolveTaskWithFilter(ctx context.Context, c client.TaskService, taskID, taskListName string, filter client.TaskFilter) (*client.Task, string, error) {
 	if taskID != "" {
 		return resolveTaskByID(ctx, c, taskID, taskListName)
 	}
-	return resolveTaskInteractive(ctx, c, taskListName)
+	return resolveTaskInteractive(ctx, c, taskListName, filter)
 }
 
-func resolveTaskByID(ctx context.Context, c *client.Client, taskID, taskListName string) (*client.Task, string, error) {
+func resolveTaskByID(ctx context.Context, c client.TaskService, taskID, taskListName string) (*client.Task, string, error) {
 	if taskListName != "" {
 		taskListID, err := c.ResolveTaskListID(ctx, taskListName)
 		if err != nil {
@@ -38,7 +44,7 @@ func resolveTaskByID(ctx context.Context, c *client.Client, taskID, taskListName
 	return task, task.TaskListID, nil
 }
 
-func resolveTaskInteractive(ctx context.Context, c *client.Client, taskListName string) (*client.Task, string, error) {
+func resolveTaskInteractive(ctx context.Context, c client.TaskService, taskListName string, filter client.TaskFilter) (*client.Task, string, error) {
 	var tasks []*client.Task
 	var taskListID string
 	var err error
@@ -48,9 +54,9 @@ func resolveTaskInteractive(ctx context.Context, c *client.Client, taskListName
 		if err != nil {
 			return nil, "", err
 		}
-		tasks, err = c.ListTasks(ctx, taskListID)
+		tasks, err = c.ListTasksFiltered(ctx, taskListID, filter)
 	} else {
-		tasks, err = c.ListAllTasks(ctx)
+		tasks, err = c.ListAllTasksFiltered(ctx, filter)
 	}
 	if err != nil {
 		return nil, "", err
@@ -0,0 +1,56 @@
+package command
+
+import (
+	"github.com/t3yamoto/gt/internal/client"
+	"github.com/t3yamoto/gt/internal/config"
+	"github.com/t3yamoto/gt/internal/profile"
+	"github.com/urfave/cli/v2"
+)
+
+// ServiceFactory creates the task service a command runs against for a profile
+type ServiceFactory func(c *cli.Context, p profile.Profile) (client.TaskService, error)
+
+var serviceFactory ServiceFactory = defaultServiceFactory
+
+// SetServiceFactory replaces how commands create their task service,
+// e.g. to run them against a client.MemoryService in tests
+func SetServiceFactory(f ServiceFactory) {
+	serviceFactory = f
+}
+
+// defaultServiceFactory connects to the Google Tasks API, or to the endpoint given by
+// --api-url. The cache is disabled for custom endpoints so it never mixes in real data.
+func defaultServiceFactory(c *cli.Context, p profile.Profile) (client.TaskService, error) {
+	retry := client.DefaultRetryOptions()
+	retry.MaxAttempts = config.MaxAttempts()
+
+	opts := []client.Option{client.WithProfile(p), client.WithRetry(retry)}
+	if apiURL := c.String("api-url"); apiURL != "" {
+		opts = append(opts, client.WithBaseURL(apiURL), client.WithoutCache())
+	}
+	taskClient, err := client.NewClient(c.Context, opts...)
+	if err != nil {
+		return nil, err
+	}
+	taskClient.SetPageOptions(client.PageOptions{MaxResults: int64(config.PageSize())})
+	taskClient.SetWorkers(config.Workers())
+	return taskClient, nil
+}
+
+// newTaskService creates the task service for a command using the current profile
+func newTaskService(c *cli.Context) (client.TaskService, error) {
+	return serviceFactory(c, profile.Current())
+}
+
+// newTaskServiceForProfile creates the task service for a command using the given profile
+func newTaskServiceForProfile(c *cli.Context, p profile.Profile) (client.TaskService, error) {
+	return serviceFactory(c, p)
+}
+
+// targetTaskList returns the --tasklist flag, or the configured default list if it is not given
+func targetTaskList(c *cli.Context) string {
+	if name := c.String("tasklist"); name != "" {
+		return name
+	}
+	return config.DefaultList()
+}
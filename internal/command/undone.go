@@ -0,0 +1,54 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/t3yamoto/gt/internal/client"
+	"github.com/urfave/cli/v2"
+)
+
+func UndoneCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "undone",
+		Usage:     "Reopen a completed task (interactive selection if no argument)",
+		ArgsUsage: "[task-id]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "tasklist",
+				Aliases: []string{"l"},
+				Usage:   "Target task list name (default: all lists)",
+			},
+			&cli.IntFlag{
+				Name:  "days",
+				Value: 7,
+				Usage: "Offer tasks completed within this many days in interactive selection",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx := c.Context
+
+			taskClient, err := newTaskService(c)
+			if err != nil {
+				return err
+			}
+
+			filter := client.TaskFilter{
+				CompletedOnly: true,
+				CompletedMin:  time.Now().AddDate(0, 0, -c.Int("days")).Format("2006-01-02"),
+			}
+			task, taskListID, err := ResolveTaskWithFilter(ctx, taskClient, c.Args().First(), c.String("tasklist"), filter)
+			if err != nil {
+				return err
+			}
+
+			reopened, err := taskClient.UncompleteTask(ctx, taskListID, task.ID)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Task reopened: %s\n", reopened.Title)
+			return nil
+		},
+	}
+}
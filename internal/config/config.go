@@ -0,0 +1,385 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configDir  = "gt"
+	configFile = "config.yaml"
+)
+
+// Sources a setting's value can come from, in increasing order of precedence
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Setting describes a configurable value
+type Setting struct {
+	Key     string
+	Default string
+	Usage   string
+	// Env is the environment variable that overrides the file
+	Env      string
+	validate func(string) error
+}
+
+// settings lists every supported key
+var settings = []Setting{
+	{Key: "default_list", Default: "@default", Usage: "Task list used by add and clear when -l is not given", validate: nonEmpty},
+	{Key: "editor", Default: "vi", Usage: "Editor used when $EDITOR is not set", validate: nonEmpty},
+	{Key: "cache_ttl", Default: "5m", Usage: "How long cached tasks are used before syncing (0 to always sync)", validate: validDuration},
+	{Key: "date_format", Default: "2006-01-02", Usage: "Go time layout for dates in tables", validate: nonEmpty},
+	{Key: "title_width", Default: "32", Usage: "Width of the TITLE table column", validate: minInt(4)},
+	{Key: "list_width", Default: "16", Usage: "Width of the LIST table column", validate: minInt(4)},
+	{Key: "token_store", Default: "file", Usage: "Where to keep OAuth tokens: file, keyring or encrypted", validate: oneOf("file", "keyring", "encrypted")},
+	{Key: "max_attempts", Default: "5", Usage: "Maximum attempts per API request", validate: minInt(1)},
+	{Key: "page_size", Default: "100", Usage: "Tasks requested per API page (1-100)", validate: intBetween(1, 100)},
+	{Key: "max_pages", Default: "0", Usage: "Pages fetched per list by gt list (0 for no limit)", validate: minInt(0)},
+	{Key: "workers", Default: "4", Usage: "Task lists fetched concurrently", validate: minInt(1)},
+}
+
+func init() {
+	for i := range settings {
+		settings[i].Env = "GT_" + strings.ToUpper(settings[i].Key)
+	}
+}
+
+// Value is the effective value of a setting and where it came from
+type Value struct {
+	Value  string
+	Source string
+}
+
+// current holds the effective configuration
+var current = defaults()
+
+// loadedPath is the config file read by Load and written by Set
+var loadedPath string
+
+func defaults() map[string]Value {
+	values := make(map[string]Value, len(settings))
+	for _, s := range settings {
+		values[s.Key] = Value{Value: s.Default, Source: SourceDefault}
+	}
+	return values
+}
+
+// Settings returns all supported settings
+func Settings() []Setting {
+	return settings
+}
+
+// Path returns the config file path, under $XDG_CONFIG_HOME if it is set
+func Path() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, configDir, configFile), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", configDir, configFile), nil
+}
+
+// FileError is returned by Load when the config file cannot be used. The file is
+// ignored, but the rest of the configuration is loaded, so gt can still run to fix it.
+type FileError struct {
+	Err error
+}
+
+func (e *FileError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// Load reads the config file at path, then applies environment variables and the
+// given key=value overrides from the command line. A config file that cannot be read
+// or has invalid settings is skipped with a *FileError once everything else is loaded.
+func Load(path string, overrides []string) error {
+	values := defaults()
+
+	var fileErr error
+	file, err := ReadFile(path)
+	if err != nil {
+		fileErr = &FileError{Err: err}
+	}
+	for key, value := range file {
+		values[key] = Value{Value: value, Source: SourceFile}
+	}
+
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.Env); ok && value != "" {
+			if err := s.validate(value); err != nil {
+				return fmt.Errorf("invalid %s: %w", s.Env, err)
+			}
+			values[s.Key] = Value{Value: value, Source: SourceEnv}
+		}
+	}
+
+	for _, o := range overrides {
+		key, value, ok := strings.Cut(o, "=")
+		if !ok {
+			return fmt.Errorf("invalid option '%s', expected key=value", o)
+		}
+		if err := Validate(key, value); err != nil {
+			return err
+		}
+		values[key] = Value{Value: value, Source: SourceFlag}
+	}
+
+	current = values
+	loadedPath = path
+	return fileErr
+}
+
+// File returns the path of the loaded config file
+func File() string {
+	return loadedPath
+}
+
+// Override sets a value for this invocation, e.g. from a dedicated command-line flag
+func Override(key, value string) error {
+	if err := Validate(key, value); err != nil {
+		return err
+	}
+	current[key] = Value{Value: value, Source: SourceFlag}
+	return nil
+}
+
+// Get returns the effective value of a setting
+func Get(key string) (Value, error) {
+	if _, err := lookup(key); err != nil {
+		return Value{}, err
+	}
+	return current[key], nil
+}
+
+// Validate checks that key is a known setting and value is valid for it
+func Validate(key, value string) error {
+	s, err := lookup(key)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(value); err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return nil
+}
+
+// ReadFile returns the settings stored in the config file, or none if it does not exist
+func ReadFile(path string) (map[string]string, error) {
+	file, err := readRaw(path)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range file {
+		if err := Validate(key, value); err != nil {
+			return nil, fmt.Errorf("%s: %w (fix it with `gt config set` or `gt config unset`)", path, err)
+		}
+	}
+	return file, nil
+}
+
+// readRaw returns the key/value pairs of the config file without validating them
+func readRaw(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	file := map[string]string{}
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// Set stores a setting in the loaded config file, or removes it if value is empty.
+// Invalid settings already in the file are kept, so they can be fixed one by one.
+func Set(key, value string) error {
+	path := loadedPath
+	if path == "" {
+		return errors.New("no config file loaded")
+	}
+
+	file, err := readRaw(path)
+	if err != nil {
+		return fmt.Errorf("%w; fix or remove the file", err)
+	}
+
+	if value != "" {
+		if err := Validate(key, value); err != nil {
+			return err
+		}
+	} else if _, ok := file[key]; !ok {
+		// Unknown keys can be removed, but only if they are in the file
+		if _, err := lookup(key); err != nil {
+			return err
+		}
+	}
+
+	if value == "" {
+		delete(file, key)
+	} else {
+		file[key] = value
+	}
+
+	b, err := yaml.Marshal(file)
+	if err != nil {
+		return err
+	}
+	if len(file) == 0 {
+		b = nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// DefaultList returns the task list used when none is given
+func DefaultList() string {
+	return current["default_list"].Value
+}
+
+// Editor returns the editor used when $EDITOR is not set
+func Editor() string {
+	return current["editor"].Value
+}
+
+// CacheTTL returns how long cached tasks are used before syncing
+func CacheTTL() time.Duration {
+	d, _ := time.ParseDuration(current["cache_ttl"].Value)
+	return d
+}
+
+// DateFormat returns the Go time layout used for dates in tables
+func DateFormat() string {
+	return current["date_format"].Value
+}
+
+// TitleWidth returns the width of the TITLE table column
+func TitleWidth() int {
+	return intValue("title_width")
+}
+
+// ListWidth returns the width of the LIST table column
+func ListWidth() int {
+	return intValue("list_width")
+}
+
+// TokenStore returns the token store backend
+func TokenStore() string {
+	return current["token_store"].Value
+}
+
+// MaxAttempts returns the maximum number of attempts per API request
+func MaxAttempts() int {
+	return intValue("max_attempts")
+}
+
+// PageSize returns the number of tasks requested per API page
+func PageSize() int {
+	return intValue("page_size")
+}
+
+// MaxPages returns the number of pages gt list fetches per list, or 0 for no limit
+func MaxPages() int {
+	return intValue("max_pages")
+}
+
+// Workers returns the number of task lists fetched concurrently
+func Workers() int {
+	return intValue("workers")
+}
+
+func intValue(key string) int {
+	n, _ := strconv.Atoi(current[key].Value)
+	return n
+}
+
+func lookup(key string) (Setting, error) {
+	for _, s := range settings {
+		if s.Key == key {
+			return s, nil
+		}
+	}
+
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.Key
+	}
+	sort.Strings(keys)
+	return Setting{}, fmt.Errorf("unknown setting '%s', valid settings: %s", key, strings.Join(keys, ", "))
+}
+
+func nonEmpty(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errors.New("must not be empty")
+	}
+	return nil
+}
+
+func validDuration(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a duration like 30s or 5m", value)
+	}
+	if d < 0 {
+		return errors.New("must not be negative")
+	}
+	return nil
+}
+
+func minInt(min int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < min {
+			return fmt.Errorf("'%s' is not a whole number of at least %d", value, min)
+		}
+		return nil
+	}
+}
+
+func intBetween(min, max int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < min || n > max {
+			return fmt.Errorf("'%s' is not a whole number from %d to %d", value, min, max)
+		}
+		return nil
+	}
+}
+
+func oneOf(allowed ...string) func(string) error {
+	return func(value string) error {
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("'%s' must be one of %s", value, strings.Join(allowed, ", "))
+	}
+}
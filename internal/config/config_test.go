@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// isolate clears the GT_* environment and restores the loaded configuration afterwards
+func isolate(t *testing.T) string {
+	t.Helper()
+	for _, s := range settings {
+		t.Setenv(s.Env, "")
+	}
+	t.Cleanup(func() {
+		current = defaults()
+		loadedPath = ""
+	})
+	return filepath.Join(t.TempDir(), configFile)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		env       map[string]string
+		overrides []string
+		want      map[string]Value
+		fileErr   bool
+		err       bool
+	}{
+		{
+			name: "defaults",
+			want: map[string]Value{"title_width": {"32", SourceDefault}, "workers": {"4", SourceDefault}},
+		},
+		{
+			name: "file over default",
+			file: "title_width: 40\n",
+			want: map[string]Value{"title_width": {"40", SourceFile}, "workers": {"4", SourceDefault}},
+		},
+		{
+			name: "env over file",
+			file: "title_width: 40\nworkers: 2\n",
+			env:  map[string]string{"GT_TITLE_WIDTH": "50"},
+			want: map[string]Value{"title_width": {"50", SourceEnv}, "workers": {"2", SourceFile}},
+		},
+		{
+			name:      "flag over env",
+			file:      "title_width: 40\n",
+			env:       map[string]string{"GT_TITLE_WIDTH": "50", "GT_WORKERS": "3"},
+			overrides: []string{"title_width=60"},
+			want:      map[string]Value{"title_width": {"60", SourceFlag}, "workers": {"3", SourceEnv}},
+		},
+		{
+			name:      "invalid file ignored",
+			file:      "title_width: 2\nlist_width: 20\n",
+			env:       map[string]string{"GT_WORKERS": "3"},
+			overrides: []string{"max_pages=1"},
+			want: map[string]Value{
+				"title_width": {"32", SourceDefault},
+				"list_width":  {"16", SourceDefault},
+				"workers":     {"3", SourceEnv},
+				"max_pages":   {"1", SourceFlag},
+			},
+			fileErr: true,
+		},
+		{
+			name:    "unknown key in file",
+			file:    "title_width: 40\nbogus: 1\n",
+			want:    map[string]Value{"title_width": {"32", SourceDefault}},
+			fileErr: true,
+		},
+		{
+			name:      "unparseable file",
+			file:      "title_width: [\n",
+			env:       map[string]string{"GT_WORKERS": "3"},
+			overrides: []string{"title_width=60"},
+			want:      map[string]Value{"title_width": {"60", SourceFlag}, "workers": {"3", SourceEnv}},
+			fileErr:   true,
+		},
+		{
+			name: "invalid env",
+			env:  map[string]string{"GT_WORKERS": "0"},
+			err:  true,
+		},
+		{
+			name:      "invalid flag",
+			overrides: []string{"title_width=wide"},
+			err:       true,
+		},
+		{
+			name:      "flag without value",
+			overrides: []string{"title_width"},
+			err:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := isolate(t)
+			if tt.file != "" {
+				if err := os.WriteFile(path, []byte(tt.file), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			err := Load(path, tt.overrides)
+			var fileErr *FileError
+			if errors.As(err, &fileErr) != tt.fileErr {
+				t.Fatalf("err = %v, want a *FileError: %t", err, tt.fileErr)
+			}
+			if tt.err != (err != nil && !tt.fileErr) {
+				t.Fatalf("err = %v, want an error: %t", err, tt.err)
+			}
+			for key, want := range tt.want {
+				if got, _ := Get(key); got != want {
+					t.Errorf("%s = %+v, want %+v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSetKeepsInvalidKeys(t *testing.T) {
+	path := isolate(t)
+	if err := os.WriteFile(path, []byte("title_width: 2\nbogus: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var fileErr *FileError
+	if err := Load(path, nil); !errors.As(err, &fileErr) {
+		t.Fatalf("Load() = %v, want a *FileError", err)
+	}
+
+	// Setting another key keeps the invalid ones for the user to fix
+	if err := Set("list_width", "20"); err != nil {
+		t.Fatal(err)
+	}
+	file, err := readRaw(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"title_width": "2", "bogus": "1", "list_width": "20"}
+	if len(file) != len(want) {
+		t.Errorf("file = %v, want %v", file, want)
+	}
+	for key, value := range want {
+		if file[key] != value {
+			t.Errorf("%s = %q, want %q", key, file[key], value)
+		}
+	}
+
+	// Invalid values are still rejected
+	if err := Set("list_width", "2"); err == nil {
+		t.Error("Set(list_width, 2) succeeded, want an error")
+	}
+	if err := Set("other", "1"); err == nil {
+		t.Error("Set(other, 1) succeeded, want an error")
+	}
+
+	// Fixing the invalid keys one by one makes the file valid again
+	if err := Set("title_width", "40"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("bogus", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(path, nil); err != nil {
+		t.Fatalf("Load() after fixing = %v", err)
+	}
+	if got, _ := Get("title_width"); got != (Value{"40", SourceFile}) {
+		t.Errorf("title_width = %+v, want 40 from the file", got)
+	}
+}
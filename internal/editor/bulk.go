@@ -0,0 +1,175 @@
+package editor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/t3yamoto/gt/internal/client"
+	"gopkg.in/yaml.v3"
+)
+
+// BulkFrontMatter is the front matter of one task in a bulk-edit document
+type BulkFrontMatter struct {
+	ID        string `yaml:"id,omitempty"`
+	Title     string `yaml:"title"`
+	Due       string `yaml:"due,omitempty"`
+	Parent    string `yaml:"parent,omitempty"`
+	Completed bool   `yaml:"completed"`
+}
+
+// BulkTask is one task of a parsed bulk-edit document
+type BulkTask struct {
+	FrontMatter BulkFrontMatter
+	Body        string // Notes content
+}
+
+// bulkKeyPattern matches the first line of a front matter block, telling a block
+// delimiter apart from a horizontal rule in the notes
+var bulkKeyPattern = regexp.MustCompile(`^(id|title|due|parent|completed)\s*:`)
+
+// notesDelimiterPattern matches a line of notes that reads as a block delimiter, or
+// such a line escaped with one or more backslashes
+var notesDelimiterPattern = regexp.MustCompile(`^\\*---[ \t\r]*$`)
+
+const bulkHeader = `# Editing %d task(s) in %s.
+# Each task is a front matter block followed by its notes. Reorder blocks to
+# reorder tasks, remove a block to delete its task, and add a block without an
+# id to add a task. Set parent to another task's id to make a subtask.
+`
+
+// GenerateBulkMarkdown creates a document with one block per task, in the given order
+func GenerateBulkMarkdown(tasks []*client.Task, taskListName string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, bulkHeader, len(tasks), strings.Join(strings.Fields(taskListName), " "))
+
+	for _, t := range tasks {
+		sb.WriteString("\n---\n")
+		if t.ID != "" {
+			sb.WriteString("id: " + yamlValue(client.ShortID(t.ID)) + "\n")
+		}
+		sb.WriteString("title: " + yamlValue(t.Title) + "\n")
+		if t.Due != "" {
+			sb.WriteString("due: " + yamlValue(t.Due) + "\n")
+		}
+		if t.Parent != "" {
+			sb.WriteString("parent: " + yamlValue(client.ShortID(t.Parent)) + "\n")
+		}
+		fmt.Fprintf(&sb, "completed: %t\n", t.Status == client.StatusCompleted)
+		sb.WriteString("---\n")
+		if t.Notes != "" {
+			sb.WriteString("\n" + escapeNotes(t.Notes) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// ParseBulkMarkdown parses a bulk-edit document. Comment and blank lines before the
+// first block are ignored, and a --- line starts a new block only when the next line
+// is a front matter key. Notes lines escaped as \--- are read as ---.
+func ParseBulkMarkdown(content string) ([]*BulkTask, error) {
+	lines := splitLines(content)
+
+	i := 0
+	for i < len(lines) {
+		if isDelimiter(lines[i]) {
+			break
+		}
+		line := strings.TrimSpace(lines[i])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return nil, fmt.Errorf("line %d: expected --- to start a task", i+1)
+		}
+		i++
+	}
+
+	var result []*BulkTask
+	for i < len(lines) {
+		n := len(result) + 1
+
+		// Front matter runs up to the closing ---
+		start := i + 1
+		end := start
+		for end < len(lines) && !isDelimiter(lines[end]) {
+			end++
+		}
+		if end == len(lines) {
+			return nil, fmt.Errorf("task %d: front matter end (---) not found", n)
+		}
+
+		var fm BulkFrontMatter
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[start:end], "\n")), &fm); err != nil {
+			return nil, fmt.Errorf("task %d: failed to parse front matter: %w", n, err)
+		}
+
+		// Notes run up to the next block
+		i = end + 1
+		bodyStart := i
+		for i < len(lines) && !isBulkBlockStart(lines, i) {
+			i++
+		}
+		body := unescapeNotes(trimBody(lines[bodyStart:i]))
+
+		if strings.TrimSpace(fm.Title) == "" {
+			return nil, fmt.Errorf("task %d: title is required", n)
+		}
+		due, err := client.ParseDate(fm.Due)
+		if err != nil {
+			return nil, fmt.Errorf("task %d (%s): invalid due: %w", n, fm.Title, err)
+		}
+		fm.Due = due
+
+		result = append(result, &BulkTask{FrontMatter: fm, Body: body})
+	}
+
+	return result, nil
+}
+
+// isBulkBlockStart reports whether line i opens the front matter of a task
+func isBulkBlockStart(lines []string, i int) bool {
+	if !isDelimiter(lines[i]) {
+		return false
+	}
+	return i+1 < len(lines) && bulkKeyPattern.MatchString(strings.TrimSpace(lines[i+1]))
+}
+
+// escapeNotes prefixes each line of notes that reads as a block delimiter with a
+// backslash (\---), so notes can never start a task block. Lines already starting
+// with backslashes get one more, which keeps unescapeNotes exact.
+func escapeNotes(notes string) string {
+	lines := strings.Split(notes, "\n")
+	for i, line := range lines {
+		if notesDelimiterPattern.MatchString(line) {
+			lines[i] = `\` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unescapeNotes removes the backslash escapeNotes added. A --- line typed in the
+// notes is left as it is.
+func unescapeNotes(body string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, `\`) && notesDelimiterPattern.MatchString(line) {
+			lines[i] = line[1:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ToTask converts a BulkTask to a Task; ID and Parent are the short IDs from the document
+func (bt *BulkTask) ToTask() *client.Task {
+	status := client.StatusNeedsAction
+	if bt.FrontMatter.Completed {
+		status = client.StatusCompleted
+	}
+
+	return &client.Task{
+		ID:     bt.FrontMatter.ID,
+		Title:  bt.FrontMatter.Title,
+		Notes:  bt.Body,
+		Due:    bt.FrontMatter.Due,
+		Status: status,
+		Parent: bt.FrontMatter.Parent,
+	}
+}
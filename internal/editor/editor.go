@@ -6,13 +6,15 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/t3yamoto/gt/internal/config"
 )
 
 // Open opens the specified content in the user's editor and returns the edited content
 func Open(content string) (string, error) {
 	editor := os.Getenv("EDITOR")
 	if editor == "" {
-		editor = "vi" // Default fallback
+		editor = config.Editor() // Default fallback
 	}
 
 	// Create temp file
@@ -50,11 +52,55 @@ func Open(content string) (string, error) {
 	return string(edited), nil
 }
 
+// OpenValid opens content in the editor until validate accepts the result. When it
+// is rejected, the editor is reopened with the error as a comment at the top. Saving
+// without changes, or the editor failing, gives up: the content is then kept in
+// session for `gt edit --recover` and the error is returned. An empty document is
+// returned as "" without validation.
+func OpenValid(content string, validate func(string) error, session *Session) (string, error) {
+	var lastErr error
+	for {
+		edited, err := Open(content)
+		if err != nil {
+			if lastErr == nil {
+				return "", err
+			}
+			return "", giveUp(session, StripErrorComment(content), lastErr)
+		}
+
+		edited = StripErrorComment(edited)
+		if strings.TrimSpace(edited) == "" {
+			return "", nil
+		}
+		if lastErr != nil && edited == StripErrorComment(content) {
+			return "", giveUp(session, edited, lastErr)
+		}
+
+		if lastErr = validate(edited); lastErr == nil {
+			return edited, nil
+		}
+		content = WithErrorComment(edited, lastErr)
+	}
+}
+
+// giveUp saves the rejected content for recovery and returns the error it was rejected with
+func giveUp(session *Session, content string, err error) error {
+	session.Content = content
+	session.Error = err.Error()
+	path, saveErr := SaveSession(session)
+	if saveErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", saveErr)
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Your edits were saved to %s. Run `gt edit --recover` to continue.\n", path)
+	return err
+}
+
 // GetEditorName returns the name of the configured editor
 func GetEditorName() string {
 	editor := os.Getenv("EDITOR")
 	if editor == "" {
-		return "vi"
+		editor = config.Editor()
 	}
 	return filepath.Base(editor)
 }
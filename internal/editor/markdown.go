@@ -15,6 +15,7 @@ type TaskFrontMatter struct {
 	Title     string `yaml:"title"`
 	Due       string `yaml:"due,omitempty"`
 	TaskList  string `yaml:"tasklist,omitempty"`
+	Parent    string `yaml:"parent,omitempty"`
 	Completed bool   `yaml:"completed,omitempty"`
 }
 
@@ -29,6 +30,7 @@ type templateData struct {
 	Title     string
 	Due       string
 	TaskList  string
+	Parent    string
 	Completed bool
 	Notes     string
 }
@@ -37,17 +39,20 @@ var taskTemplate = template.Must(template.New("task").Parse(`---
 title: {{.Title}}
 due: {{.Due}}
 tasklist: {{.TaskList}}
+parent: {{.Parent}}
 completed: {{.Completed}}
 ---
 
-{{.Notes}}`))
+{{if .Notes}}{{.Notes}}
+{{end}}`))
 
 // GenerateMarkdown creates a markdown document from a task
 func GenerateMarkdown(task *client.Task, taskListName string) string {
 	data := templateData{
-		Title:     task.Title,
-		Due:       task.Due,
-		TaskList:  taskListName,
+		Title:     yamlValue(task.Title),
+		Due:       yamlValue(task.Due),
+		TaskList:  yamlValue(taskListName),
+		Parent:    yamlValue(client.ShortID(task.Parent)),
 		Completed: task.Status == client.StatusCompleted,
 		Notes:     task.Notes,
 	}
@@ -59,39 +64,50 @@ func GenerateMarkdown(task *client.Task, taskListName string) string {
 
 // GenerateEmptyMarkdown creates an empty markdown template
 func GenerateEmptyMarkdown(taskListName string) string {
-	data := templateData{
-		Title:     "",
-		Due:       "",
-		TaskList:  taskListName,
-		Completed: false,
-		Notes:     "",
-	}
+	return GenerateMarkdown(&client.Task{}, taskListName)
+}
 
-	var buf bytes.Buffer
-	taskTemplate.Execute(&buf, data)
-	return buf.String()
+// yamlValue formats s as a one-line YAML scalar that parses back to s, quoting it
+// where needed (e.g. "foo: bar", "#tag" or "yes")
+func yamlValue(s string) string {
+	if s == "" {
+		return ""
+	}
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+	if strings.ContainsAny(s, "\n\r") {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	b, err := yaml.Marshal(node)
+	if err != nil {
+		return s
+	}
+	return strings.TrimSuffix(string(b), "\n")
 }
 
 // ParseMarkdown parses a markdown document into TaskMarkdown
 func ParseMarkdown(content string) (*TaskMarkdown, error) {
-	content = strings.TrimSpace(content)
+	lines := splitLines(content)
 
-	// Check for front matter
-	if !strings.HasPrefix(content, "---") {
+	// Skip leading blank lines
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start == len(lines) || !isDelimiter(lines[start]) {
 		return nil, fmt.Errorf("front matter not found, please start with ---")
 	}
 
-	// Find the end of front matter
-	rest := content[3:] // Skip opening ---
-	rest = strings.TrimLeft(rest, "\n\r")
-
-	endIdx := strings.Index(rest, "---")
-	if endIdx == -1 {
+	// The front matter ends at the next unindented --- line
+	end := start + 1
+	for end < len(lines) && !isDelimiter(lines[end]) {
+		end++
+	}
+	if end == len(lines) {
 		return nil, fmt.Errorf("front matter end (---) not found")
 	}
 
-	frontMatterStr := strings.TrimSpace(rest[:endIdx])
-	body := strings.TrimSpace(rest[endIdx+3:])
+	frontMatterStr := strings.Join(lines[start+1:end], "\n")
+	body := trimBody(lines[end+1:])
 
 	// Parse front matter YAML
 	var fm TaskFrontMatter
@@ -99,16 +115,46 @@ func ParseMarkdown(content string) (*TaskMarkdown, error) {
 		return nil, fmt.Errorf("failed to parse front matter: %w", err)
 	}
 
-	if fm.Title == "" {
+	if strings.TrimSpace(fm.Title) == "" {
 		return nil, fmt.Errorf("title is required")
 	}
 
+	due, err := client.ParseDate(fm.Due)
+	if err != nil {
+		return nil, fmt.Errorf("invalid due: %w", err)
+	}
+	fm.Due = due
+
 	return &TaskMarkdown{
 		FrontMatter: fm,
 		Body:        body,
 	}, nil
 }
 
+// splitLines splits content into lines. A document whose line breaks are all CRLF, as
+// saved by some editors, is read as LF; otherwise a \r is kept as part of its line, so
+// notes containing CRLF round-trip exactly.
+func splitLines(content string) []string {
+	if strings.Count(content, "\n") == strings.Count(content, "\r\n") {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	return strings.Split(content, "\n")
+}
+
+// isDelimiter reports whether line is a front matter delimiter. Lines of YAML block
+// values are indented, so they never match.
+func isDelimiter(line string) bool {
+	return strings.TrimRight(line, " \t\r") == "---"
+}
+
+// trimBody joins the lines after a front matter block into notes, dropping the blank
+// line written after the delimiter and the final newline, so notes round-trip exactly
+func trimBody(lines []string) string {
+	body := strings.Join(lines, "\n")
+	body = strings.TrimPrefix(body, "\n")
+	return strings.TrimSuffix(body, "\n")
+}
+
 // ToTask converts TaskMarkdown to a Task
 func (tm *TaskMarkdown) ToTask() *client.Task {
 	status := client.StatusNeedsAction
@@ -121,6 +167,7 @@ func (tm *TaskMarkdown) ToTask() *client.Task {
 		Notes:        tm.Body,
 		Due:          tm.FrontMatter.Due,
 		Status:       status,
+		Parent:       tm.FrontMatter.Parent,
 		TaskListName: tm.FrontMatter.TaskList,
 	}
 }
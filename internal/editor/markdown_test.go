@@ -0,0 +1,153 @@
+package editor
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/t3yamoto/gt/internal/client"
+)
+
+// textPieces are the fragments random text is made of, chosen to hit the edges of the
+// format: delimiters, front matter keys, YAML syntax, comments and line endings
+var textPieces = []string{
+	"a", "Buy milk", "é", "✓", " ", "  ", "\t", "\n", "\r\n", "\r", "\n\n",
+	"---", "\\---", "--- ", "-", "#", "# ", ":", ": ", "id: x", "title: y", "due: z",
+	"- [ ] ", `"`, "'", "yes", "null", "~", "{", "[", "|", ">", "&a", "*a", "!", "%", "@",
+}
+
+func randomText(r *rand.Rand, size int) string {
+	var sb strings.Builder
+	for n := r.Intn(size + 1); n > 0; n-- {
+		sb.WriteString(textPieces[r.Intn(len(textPieces))])
+	}
+	return sb.String()
+}
+
+// randomTask is a task with every field the documents carry set to random content.
+// Titles are never blank, as parsing rejects them, and IDs are already short.
+type randomTask struct {
+	*client.Task
+}
+
+func (randomTask) Generate(r *rand.Rand, size int) reflect.Value {
+	t := &client.Task{
+		ID:     randomID(r),
+		Title:  randomText(r, size),
+		Notes:  randomText(r, size),
+		Status: client.StatusNeedsAction,
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		t.Title += "x"
+	}
+	if r.Intn(2) == 0 {
+		t.Due = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, r.Intn(20000)).Format("2006-01-02")
+	}
+	if r.Intn(2) == 0 {
+		t.Parent = randomID(r)
+	}
+	if r.Intn(2) == 0 {
+		t.Status = client.StatusCompleted
+	}
+	return reflect.ValueOf(randomTask{t})
+}
+
+func randomID(r *rand.Rand) string {
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-"
+	b := make([]byte, 1+r.Intn(8))
+	for i := range b {
+		b[i] = chars[r.Intn(len(chars))]
+	}
+	return string(b)
+}
+
+var quickConfig = &quick.Config{MaxCount: 500, Rand: rand.New(rand.NewSource(1))}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	roundTrip := func(rt randomTask, listName string) bool {
+		want := *rt.Task
+		want.ID = ""
+		want.TaskListName = listName
+
+		content := GenerateMarkdown(rt.Task, listName)
+		parsed, err := ParseMarkdown(content)
+		if err != nil {
+			t.Logf("ParseMarkdown: %v\n%s", err, content)
+			return false
+		}
+		if got := parsed.ToTask(); !reflect.DeepEqual(*got, want) {
+			t.Logf("got  %#v\nwant %#v\n%s", *got, want, content)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(roundTrip, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBulkMarkdownRoundTrip(t *testing.T) {
+	roundTrip := func(rts []randomTask, listName string) bool {
+		tasks := make([]*client.Task, len(rts))
+		for i, rt := range rts {
+			tasks[i] = rt.Task
+		}
+
+		content := GenerateBulkMarkdown(tasks, listName)
+		parsed, err := ParseBulkMarkdown(content)
+		if err != nil {
+			t.Logf("ParseBulkMarkdown: %v\n%s", err, content)
+			return false
+		}
+		if len(parsed) != len(tasks) {
+			t.Logf("parsed %d task(s), want %d\n%s", len(parsed), len(tasks), content)
+			return false
+		}
+		for i, bt := range parsed {
+			if got := bt.ToTask(); !reflect.DeepEqual(got, tasks[i]) {
+				t.Logf("task %d:\ngot  %#v\nwant %#v\n%s", i+1, *got, *tasks[i], content)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(roundTrip, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseBulkMarkdownNotes(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+	}{
+		{"forged block", "---\ntitle: y"},
+		{"forged block at the end", "text\n---\nid: x\ntitle: y\n---"},
+		{"escaped delimiter", "\\---\n\\\\---"},
+		{"CRLF", "line 1\r\nline 2\r\n"},
+		{"horizontal rule", "above\n---\nbelow"},
+	}
+	for _, tt := range tests {
+		tasks := []*client.Task{
+			{ID: "a1", Title: "First", Notes: tt.notes, Status: client.StatusNeedsAction},
+			{ID: "b2", Title: "Second", Status: client.StatusNeedsAction},
+		}
+		parsed, err := ParseBulkMarkdown(GenerateBulkMarkdown(tasks, "List"))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(parsed) != 2 || parsed[0].Body != tt.notes || parsed[1].FrontMatter.Title != "Second" {
+			t.Errorf("%s: parsed %d task(s), first with notes %q", tt.name, len(parsed), parsed[0].Body)
+		}
+	}
+
+	// A --- line typed in notes is kept unless a front matter key follows
+	parsed, err := ParseBulkMarkdown("---\ntitle: One\n---\n\nabove\n---\nbelow\n")
+	if err != nil || len(parsed) != 1 || parsed[0].Body != "above\n---\nbelow" {
+		t.Errorf("typed horizontal rule: %v, %+v", err, parsed)
+	}
+}
@@ -0,0 +1,138 @@
+package editor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/t3yamoto/gt/internal/profile"
+)
+
+const recoverFile = "recover.json"
+
+// errorCommentPrefix starts the lines explaining why the last edit was rejected
+const errorCommentPrefix = "# gt: "
+
+// ErrNoSession is returned by LoadSession when there is nothing to recover
+var ErrNoSession = errors.New("no editor session to recover")
+
+// Kinds of editor sessions
+const (
+	SessionAdd  = "add"
+	SessionEdit = "edit"
+	SessionBulk = "bulk"
+)
+
+// Session is an editor session whose content could not be parsed, saved so it can
+// be resumed with `gt edit --recover`
+type Session struct {
+	Kind       string    `json:"kind"`
+	TaskListID string    `json:"tasklist_id"`
+	TaskID     string    `json:"task_id,omitempty"`
+	Completed  bool      `json:"completed,omitempty"` // whether a bulk session included completed tasks
+	Content    string    `json:"content"`
+	Error      string    `json:"error"`
+	SavedAt    time.Time `json:"saved_at"`
+}
+
+// sessionPath returns the recovery file of the current profile
+func sessionPath() (string, error) {
+	dir, err := profile.Current().CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, recoverFile), nil
+}
+
+// SaveSession stores s as the session to recover, replacing any earlier one, and
+// returns where it was written
+func SaveSession(s *Session) (string, error) {
+	path, err := sessionPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	s.SavedAt = time.Now()
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		return "", fmt.Errorf("failed to save recovery copy: %w", err)
+	}
+	return path, nil
+}
+
+// LoadSession returns the saved session, or ErrNoSession
+func LoadSession() (*Session, error) {
+	path, err := sessionPath()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoSession
+		}
+		return nil, fmt.Errorf("failed to read recovery copy: %w", err)
+	}
+
+	var s Session
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse recovery copy: %w", err)
+	}
+	return &s, nil
+}
+
+// DeleteSession removes the saved session, if any
+func DeleteSession() error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete recovery copy: %w", err)
+	}
+	return nil
+}
+
+// WithErrorComment puts err as a comment at the top of content, replacing the
+// comment from an earlier attempt
+func WithErrorComment(content string, err error) string {
+	message := "Could not save your changes:\n"
+	for _, line := range strings.Split(err.Error(), "\n") {
+		message += "  " + line + "\n"
+	}
+	message += "Fix the problem and save again, or save without changes to give up."
+	return WithComment(content, message)
+}
+
+// WithComment puts message as a comment at the top of content, replacing the comment
+// from an earlier attempt. The comment is removed again by StripErrorComment.
+func WithComment(content, message string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(message, "\n") {
+		sb.WriteString(errorCommentPrefix + line + "\n")
+	}
+	return sb.String() + StripErrorComment(content)
+}
+
+// StripErrorComment removes the comment added by WithErrorComment
+func StripErrorComment(content string) string {
+	for strings.HasPrefix(content, errorCommentPrefix) {
+		end := strings.IndexByte(content, '\n')
+		if end == -1 {
+			return ""
+		}
+		content = content[end+1:]
+	}
+	return content
+}
@@ -14,8 +14,12 @@ type TaskJSON struct {
 	Notes        string `json:"notes,omitempty"`
 	Due          string `json:"due,omitempty"`
 	Status       string `json:"status"`
+	Completed    string `json:"completed,omitempty"`
+	Parent       string `json:"parent,omitempty"`
+	Position     string `json:"position,omitempty"`
 	TaskListID   string `json:"tasklistId"`
 	TaskListName string `json:"tasklistName"`
+	Profile      string `json:"profile,omitempty"`
 }
 
 // PrintTasksJSON prints tasks in JSON format
@@ -28,8 +32,12 @@ func PrintTasksJSON(w io.Writer, tasks []*client.Task) error {
 			Notes:        t.Notes,
 			Due:          t.Due,
 			Status:       t.Status,
+			Completed:    t.Completed,
+			Parent:       t.Parent,
+			Position:     t.Position,
 			TaskListID:   t.TaskListID,
 			TaskListName: t.TaskListName,
+			Profile:      t.Profile,
 		}
 	}
 
@@ -37,3 +45,47 @@ func PrintTasksJSON(w io.Writer, tasks []*client.Task) error {
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(jsonTasks)
 }
+
+// TaskListJSON represents a task list in JSON format
+type TaskListJSON struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// TaskListStatsJSON represents a task list with task counts in JSON format
+type TaskListStatsJSON struct {
+	TaskListJSON
+	OpenTasks      int `json:"openTasks"`
+	CompletedTasks int `json:"completedTasks"`
+}
+
+// PrintTaskListsJSON prints task lists in JSON format
+func PrintTaskListsJSON(w io.Writer, lists []*client.TaskList) error {
+	jsonLists := make([]TaskListJSON, len(lists))
+	for i, l := range lists {
+		jsonLists[i] = TaskListJSON{
+			ID:    l.ID,
+			Title: l.Title,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonLists)
+}
+
+// PrintTaskListStatsJSON prints a task list with its task counts in JSON format
+func PrintTaskListStatsJSON(w io.Writer, stats *client.TaskListStats) error {
+	jsonStats := TaskListStatsJSON{
+		TaskListJSON: TaskListJSON{
+			ID:    stats.TaskList.ID,
+			Title: stats.TaskList.Title,
+		},
+		OpenTasks:      stats.OpenTasks,
+		CompletedTasks: stats.CompletedTasks,
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonStats)
+}
@@ -5,20 +5,108 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/mattn/go-runewidth"
 	"github.com/t3yamoto/gt/internal/client"
+	"github.com/t3yamoto/gt/internal/config"
 )
 
-// PrintTasksTable prints tasks in a table format
+// Column widths; the LIST and TITLE widths come from the config
+const (
+	idWidth = 8
+
+	profileWidth = 12
+
+	listIDWidth     = 32
+	listsTitleWidth = 32
+)
+
+// PrintTasksTable prints tasks in a flat table format
 func PrintTasksTable(w io.Writer, tasks []*client.Task) {
 	if len(tasks) == 0 {
 		fmt.Fprintln(w, "No tasks found.")
 		return
 	}
 
-	// Sort by DUE (empty last), then by LIST
-	sort.Slice(tasks, func(i, j int) bool {
+	sortTasks(tasks)
+
+	cols := columnsFor(tasks)
+	printHeader(w, cols)
+	for _, t := range tasks {
+		printRow(w, t, 0, cols)
+	}
+}
+
+// PrintTasksTree prints tasks in a table format with subtasks indented under their parents.
+// Tasks whose parent is not in the given slice are printed as top-level tasks.
+func PrintTasksTree(w io.Writer, tasks []*client.Task) {
+	if len(tasks) == 0 {
+		fmt.Fprintln(w, "No tasks found.")
+		return
+	}
+
+	byID := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = true
+	}
+
+	var roots []*client.Task
+	children := make(map[string][]*client.Task)
+	for _, t := range tasks {
+		if t.Parent != "" && byID[t.Parent] {
+			children[t.Parent] = append(children[t.Parent], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	sortTasks(roots)
+	for _, siblings := range children {
+		sort.SliceStable(siblings, func(i, j int) bool {
+			return siblings[i].Position < siblings[j].Position
+		})
+	}
+
+	cols := columnsFor(tasks)
+	printHeader(w, cols)
+	var printNode func(t *client.Task, depth int)
+	printNode = func(t *client.Task, depth int) {
+		printRow(w, t, depth, cols)
+		for _, child := range children[t.ID] {
+			printNode(child, depth+1)
+		}
+	}
+	for _, t := range roots {
+		printNode(t, 0)
+	}
+}
+
+// PrintTaskListsTable prints task lists in a table format
+func PrintTaskListsTable(w io.Writer, lists []*client.TaskList) {
+	if len(lists) == 0 {
+		fmt.Fprintln(w, "No task lists found.")
+		return
+	}
+
+	fmt.Fprintf(w, "%s  %s\n", padRight("ID", listIDWidth), "TITLE")
+	fmt.Fprintln(w, strings.Repeat("-", listIDWidth+listsTitleWidth+2))
+	for _, l := range lists {
+		fmt.Fprintf(w, "%s  %s\n", padRight(l.ID, listIDWidth), l.Title)
+	}
+}
+
+// PrintTaskListStats prints a task list with its task counts
+func PrintTaskListStats(w io.Writer, stats *client.TaskListStats) {
+	fmt.Fprintf(w, "ID:         %s\n", stats.TaskList.ID)
+	fmt.Fprintf(w, "Title:      %s\n", stats.TaskList.Title)
+	fmt.Fprintf(w, "Open:       %d\n", stats.OpenTasks)
+	fmt.Fprintf(w, "Completed:  %d\n", stats.CompletedTasks)
+}
+
+// sortTasks sorts tasks by DUE (empty last), then by LIST
+func sortTasks(tasks []*client.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
 		// DUE comparison (empty dates come last)
 		if tasks[i].Due != tasks[j].Due {
 			if tasks[i].Due == "" {
@@ -32,36 +120,107 @@ func PrintTasksTable(w io.Writer, tasks []*client.Task) {
 		// LIST comparison
 		return tasks[i].TaskListName < tasks[j].TaskListName
 	})
+}
 
-	// Calculate column widths
-	idWidth := 8
-	listWidth := 16
-	titleWidth := 32
-	dueWidth := 10
+// columns selects the optional table columns and holds the configurable widths
+type columns struct {
+	profile   bool
+	completed bool
 
-	// Print header
-	fmt.Fprintf(w, "%s  %s  %s  %s\n",
-		padRight("ID", idWidth),
-		padRight("LIST", listWidth),
-		padRight("TITLE", titleWidth),
-		"DUE")
-	fmt.Fprintln(w, strings.Repeat("-", idWidth+listWidth+titleWidth+dueWidth+6))
+	list  int
+	title int
+	due   int
+	done  int
+}
 
-	// Print tasks
+// columnsFor shows the PROFILE and COMPLETED columns only if some task has a value for them.
+// The date columns are as wide as a formatted sample date.
+func columnsFor(tasks []*client.Task) columns {
+	sample := time.Date(2006, 12, 30, 23, 59, 0, 0, time.Local)
+	cols := columns{
+		list:  config.ListWidth(),
+		title: config.TitleWidth(),
+		due:   runewidth.StringWidth(formatDate(sample)),
+		done:  runewidth.StringWidth(formatCompleted(sample.Format(time.RFC3339))),
+	}
 	for _, t := range tasks {
-		list := truncate(t.TaskListName, listWidth)
-		title := truncate(t.Title, titleWidth)
-		due := t.Due
-		if due == "" {
-			due = "-"
+		if t.Profile != "" {
+			cols.profile = true
 		}
+		if t.Completed != "" {
+			cols.completed = true
+		}
+	}
+	return cols
+}
+
+// printHeader prints the table header
+func printHeader(w io.Writer, cols columns) {
+	var cells []string
+	width := idWidth + cols.list + cols.title + cols.due + 6
+	if cols.profile {
+		cells = append(cells, padRight("PROFILE", profileWidth))
+		width += profileWidth + 2
+	}
+	cells = append(cells,
+		padRight("ID", idWidth),
+		padRight("LIST", cols.list),
+		padRight("TITLE", cols.title))
+	if cols.completed {
+		cells = append(cells, padRight("DUE", cols.due), "COMPLETED")
+		width += cols.done + 2
+	} else {
+		cells = append(cells, "DUE")
+	}
+
+	fmt.Fprintln(w, strings.Join(cells, "  "))
+	fmt.Fprintln(w, strings.Repeat("-", width))
+}
+
+// printRow prints a single task row, indenting the title by depth
+func printRow(w io.Writer, t *client.Task, depth int, cols columns) {
+	list := truncate(t.TaskListName, cols.list)
+	title := truncate(strings.Repeat("  ", depth)+t.Title, cols.title)
+	due := "-"
+	if d, err := time.Parse("2006-01-02", t.Due); err == nil {
+		due = formatDate(d)
+	} else if t.Due != "" {
+		due = t.Due
+	}
+
+	var cells []string
+	if cols.profile {
+		cells = append(cells, padRight(truncate(t.Profile, profileWidth), profileWidth))
+	}
+	cells = append(cells,
+		padRight(client.ShortID(t.ID), idWidth),
+		padRight(list, cols.list),
+		padRight(title, cols.title))
+	if cols.completed {
+		completed := "-"
+		if t.Completed != "" {
+			completed = formatCompleted(t.Completed)
+		}
+		cells = append(cells, padRight(due, cols.due), completed)
+	} else {
+		cells = append(cells, due)
+	}
+
+	fmt.Fprintln(w, strings.Join(cells, "  "))
+}
+
+// formatDate formats a date with the configured date format
+func formatDate(t time.Time) string {
+	return t.Format(config.DateFormat())
+}
 
-		fmt.Fprintf(w, "%s  %s  %s  %s\n",
-			padRight(client.ShortID(t.ID), idWidth),
-			padRight(list, listWidth),
-			padRight(title, titleWidth),
-			due)
+// formatCompleted converts an API timestamp to local time for display
+func formatCompleted(apiTime string) string {
+	t, err := time.Parse(time.RFC3339, apiTime)
+	if err != nil {
+		return client.ParseDueDate(apiTime)
 	}
+	return t.Local().Format(config.DateFormat() + " 15:04")
 }
 
 // padRight pads a string to the specified display width
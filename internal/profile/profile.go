@@ -0,0 +1,213 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	appDir      = "gt"
+	cacheDir    = ".cache/gt"
+	profilesDir = "profiles"
+	currentFile = "profile"
+
+	// DefaultName is the profile that uses the original, unprefixed paths
+	DefaultName = "default"
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Profile is a named account with its own credentials, token and cache
+type Profile struct {
+	Name string
+}
+
+// current is the profile used when none is given explicitly
+var current = Profile{Name: DefaultName}
+
+// Current returns the active profile
+func Current() Profile {
+	return current
+}
+
+// SetCurrent makes the named profile active for this invocation
+func SetCurrent(name string) error {
+	p, err := Get(name)
+	if err != nil {
+		return err
+	}
+	current = p
+	return nil
+}
+
+// Get returns the named profile, checking that it exists
+func Get(name string) (Profile, error) {
+	if err := validate(name); err != nil {
+		return Profile{}, err
+	}
+
+	p := Profile{Name: name}
+	if p.IsDefault() {
+		return p, nil
+	}
+
+	dir, err := p.ConfigDir()
+	if err != nil {
+		return Profile{}, err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return Profile{}, fmt.Errorf("profile '%s' not found, create it with `gt profile add %s`", name, name)
+	}
+	return p, nil
+}
+
+// Resolve returns the profile name to use: the explicit name if given (from --profile
+// or GT_PROFILE), otherwise the one selected with `gt profile use`, otherwise the default
+func Resolve(name string) string {
+	if name != "" {
+		return name
+	}
+	if saved := Saved(); saved != "" {
+		return saved
+	}
+	return DefaultName
+}
+
+// IsDefault reports whether this is the default profile
+func (p Profile) IsDefault() bool {
+	return p.Name == "" || p.Name == DefaultName
+}
+
+// ConfigDir returns the directory holding the profile's credentials and token
+func (p Profile) ConfigDir() (string, error) {
+	base, err := configBase()
+	if err != nil {
+		return "", err
+	}
+	if p.IsDefault() {
+		return base, nil
+	}
+	return filepath.Join(base, profilesDir, p.Name), nil
+}
+
+// CacheDir returns the directory holding the profile's cache
+func (p Profile) CacheDir() (string, error) {
+	base, err := homeDir(cacheDir)
+	if err != nil {
+		return "", err
+	}
+	if p.IsDefault() {
+		return base, nil
+	}
+	return filepath.Join(base, profilesDir, p.Name), nil
+}
+
+// SharedConfigDir returns the top-level config directory, whose credentials.json is
+// used by profiles that have none of their own
+func SharedConfigDir() (string, error) {
+	return configBase()
+}
+
+// List returns the names of all profiles, starting with the default one
+func List() ([]string, error) {
+	base, err := configBase()
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{DefaultName}
+	entries, err := os.ReadDir(filepath.Join(base, profilesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var named []string
+	for _, e := range entries {
+		if e.IsDir() && validName.MatchString(e.Name()) {
+			named = append(named, e.Name())
+		}
+	}
+	sort.Strings(named)
+	return append(names, named...), nil
+}
+
+// Add creates a new profile
+func Add(name string) (Profile, error) {
+	if err := validate(name); err != nil {
+		return Profile{}, err
+	}
+
+	p := Profile{Name: name}
+	if p.IsDefault() {
+		return Profile{}, fmt.Errorf("profile '%s' already exists", name)
+	}
+
+	dir, err := p.ConfigDir()
+	if err != nil {
+		return Profile{}, err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return Profile{}, fmt.Errorf("profile '%s' already exists", name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Profile{}, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	return p, nil
+}
+
+// Use saves the named profile as the one used when no profile is given
+func Use(name string) error {
+	if _, err := Get(name); err != nil {
+		return err
+	}
+
+	base, err := configBase()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(base, 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(base, currentFile), []byte(name+"\n"), 0600)
+}
+
+// Saved returns the profile selected with `gt profile use`, or "" if none
+func Saved() string {
+	base, err := configBase()
+	if err != nil {
+		return ""
+	}
+	b, err := os.ReadFile(filepath.Join(base, currentFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func validate(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s': use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// configBase returns gt's config directory, under $XDG_CONFIG_HOME if it is set like
+// the config file
+func configBase() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, appDir), nil
+	}
+	return homeDir(filepath.Join(".config", appDir))
+}
+
+func homeDir(dir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, dir), nil
+}
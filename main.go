@@ -1,10 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 
+	"github.com/t3yamoto/gt/internal/auth"
 	"github.com/t3yamoto/gt/internal/command"
+	"github.com/t3yamoto/gt/internal/config"
+	"github.com/t3yamoto/gt/internal/profile"
 	"github.com/urfave/cli/v2"
 )
 
@@ -15,12 +20,60 @@ func main() {
 		Name:    "gt",
 		Usage:   "Google Tasks CLI",
 		Version: version,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "profile",
+				Aliases: []string{"P"},
+				EnvVars: []string{"GT_PROFILE"},
+				Usage:   "Account profile to use (default: the one set with `gt profile use`)",
+			},
+			&cli.StringFlag{
+				Name:    "config",
+				EnvVars: []string{"GT_CONFIG"},
+				Usage:   "Config file (default: $XDG_CONFIG_HOME/gt/config.yaml or ~/.config/gt/config.yaml)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "option",
+				Aliases: []string{"o"},
+				Usage:   "Override a setting for this command, e.g. -o cache_ttl=0 (repeatable)",
+			},
+			&cli.StringFlag{
+				Name:  "token-store",
+				Usage: "Where to keep OAuth tokens: file, keyring or encrypted (setting token_store)",
+			},
+			&cli.StringFlag{
+				Name:    "api-url",
+				EnvVars: []string{"GT_API_BASE_URL"},
+				Usage:   "Tasks REST API base URL (for testing against a local server)",
+				Hidden:  true,
+			},
+			&cli.IntFlag{
+				Name:  "max-attempts",
+				Usage: "Maximum attempts per API request when rate limited or on server errors (setting max_attempts)",
+			},
+		},
+		Before: func(c *cli.Context) error {
+			if err := loadConfig(c); err != nil {
+				return err
+			}
+			if err := auth.SetTokenStore(config.TokenStore()); err != nil {
+				return err
+			}
+			return setProfile(c)
+		},
 		Commands: []*cli.Command{
 			command.ListCommand(),
 			command.AddCommand(),
 			command.DoneCommand(),
+			command.UndoneCommand(),
 			command.EditCommand(),
 			command.DeleteCommand(),
+			command.MoveCommand(),
+			command.ClearCommand(),
+			command.ListsCommand(),
+			command.AuthCommand(),
+			command.ProfileCommand(),
+			command.ConfigCommand(),
 		},
 		Action: func(c *cli.Context) error {
 			// Default action: run list command
@@ -33,3 +86,46 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// setProfile activates the profile given with --profile, or else the one saved with
+// `gt profile use`. A saved profile that no longer exists falls back to the default
+// one, so that `gt profile use` can still be run to fix it.
+func setProfile(c *cli.Context) error {
+	name := profile.Resolve(c.String("profile"))
+	err := profile.SetCurrent(name)
+	if err == nil || c.String("profile") != "" {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Warning: saved profile '%s' not found, using '%s' (change it with `gt profile use`)\n", name, profile.DefaultName)
+	return nil
+}
+
+// loadConfig loads the config file, then applies -o options and dedicated flags on top
+func loadConfig(c *cli.Context) error {
+	path := c.String("config")
+	if path == "" {
+		var err error
+		if path, err = config.Path(); err != nil {
+			return err
+		}
+	}
+	if err := config.Load(path, c.StringSlice("option")); err != nil {
+		var fileErr *config.FileError
+		if !errors.As(err, &fileErr) {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: ignoring config file: %v\n", err)
+	}
+
+	if c.IsSet("token-store") {
+		if err := config.Override("token_store", c.String("token-store")); err != nil {
+			return err
+		}
+	}
+	if c.IsSet("max-attempts") {
+		if err := config.Override("max_attempts", strconv.Itoa(c.Int("max-attempts"))); err != nil {
+			return err
+		}
+	}
+	return nil
+}